@@ -0,0 +1,71 @@
+-- SQLite3
+
+insert into accounts(code, name, is_bs, is_left)
+values
+/* Balance Sheet */
+-- Assets
+(1110, 'Cash and Deposits', TRUE, TRUE),
+(1120, 'Accounts Receivable', TRUE, TRUE),
+(1130, 'Merchandise', TRUE, TRUE),
+(1190, 'Suspense', TRUE, TRUE),
+(1210, 'Tangible Fixed Assets', TRUE, TRUE),
+(1211, 'Machinery and Equipment', TRUE, TRUE),
+-- Liabilities
+(2100, 'Accounts Payable', TRUE, FALSE),
+(2101, 'Short-term Borrowings', TRUE, FALSE),
+(2102, 'Income Taxes Payable', TRUE, FALSE),
+(2103, 'Deposits Received', TRUE, FALSE),
+(2200, 'Long-term Borrowings', TRUE, FALSE),
+-- Equity
+(3100, 'Capital Stock', TRUE, FALSE),
+(3200, 'Capital Surplus', TRUE, FALSE),
+(3300, 'Retained Earnings', TRUE, FALSE),
+
+/* Profit and Loss */
+-- Revenue
+(4100, 'Merchandise Sales', FALSE, FALSE),
+-- Cost of Sales
+(5100, 'Beginning Merchandise Inventory', FALSE, TRUE),
+(5200, 'Merchandise Purchases', FALSE, TRUE),
+(5300, 'Ending Merchandise Inventory', FALSE, TRUE),
+-- Selling, General and Administrative Expenses
+(7200, 'Salaries and Bonuses', FALSE, TRUE),
+(7300, 'Expenses', FALSE, TRUE),
+-- Non-operating and Extraordinary Items
+(8100, 'Non-operating Income', FALSE, FALSE),
+(8200, 'Non-operating Expenses', FALSE, TRUE),
+(8300, 'Extraordinary Gains', FALSE, FALSE),
+(8400, 'Extraordinary Losses', FALSE, TRUE),
+-- Income Taxes
+(9000, 'Income Taxes', FALSE, TRUE)
+;
+
+-- names in ja
+insert into account_names(code, lang, name)
+values
+(1110, 'ja', '現金及び預金'),
+(1120, 'ja', '売掛金'),
+(1130, 'ja', '商品'),
+(1190, 'ja', '仮勘定'),
+(1210, 'ja', '有形固定資産'),
+(1211, 'ja', '機械装置'),
+(2100, 'ja', '買掛金'),
+(2101, 'ja', '短期借入金'),
+(2102, 'ja', '未払い法人税等'),
+(2103, 'ja', '預り金'),
+(2200, 'ja', '長期借入金'),
+(3100, 'ja', '資本金'),
+(3200, 'ja', '資本剰余金'),
+(3300, 'ja', '繰越利益剰余金'),
+(4100, 'ja', '商品売上高'),
+(5100, 'ja', '期首商品棚卸高'),
+(5200, 'ja', '商品仕入高'),
+(5300, 'ja', '期末商品棚卸高'),
+(7200, 'ja', '給与・賞与'),
+(7300, 'ja', '経費'),
+(8100, 'ja', '営業外収益'),
+(8200, 'ja', '営業外費用'),
+(8300, 'ja', '特別利益'),
+(8400, 'ja', '特別損失'),
+(9000, 'ja', '法人税等')
+;
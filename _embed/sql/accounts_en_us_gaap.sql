@@ -0,0 +1,89 @@
+-- SQLite3
+
+insert into accounts(code, name, is_bs, is_left)
+values
+/* Balance Sheet */
+-- Assets
+(1110, 'Cash and Cash Equivalents', TRUE, TRUE),
+(1120, 'Accounts Receivable', TRUE, TRUE),
+(1130, 'Inventory', TRUE, TRUE),
+(1140, 'Prepaid Expenses', TRUE, TRUE),
+(1190, 'Suspense', TRUE, TRUE),
+(1210, 'Property, Plant and Equipment', TRUE, TRUE),
+(1220, 'Intangible Assets', TRUE, TRUE),
+-- Liabilities
+(2100, 'Accounts Payable', TRUE, FALSE),
+(2110, 'Accrued Liabilities', TRUE, FALSE),
+(2120, 'Income Taxes Payable', TRUE, FALSE),
+(2130, 'Deferred Revenue', TRUE, FALSE),
+(2140, 'Short-term Debt', TRUE, FALSE),
+(2200, 'Long-term Debt', TRUE, FALSE),
+-- Equity
+(3100, 'Common Stock', TRUE, FALSE),
+(3200, 'Additional Paid-in Capital', TRUE, FALSE),
+(3300, 'Retained Earnings', TRUE, FALSE),
+
+/* Profit and Loss */
+-- Revenue
+(4100, 'Sales Revenue', FALSE, FALSE),
+(4200, 'Product Revenue', FALSE, FALSE),
+-- Cost of Sales
+(5100, 'Beginning Inventory', FALSE, TRUE),
+(5200, 'Purchases', FALSE, TRUE),
+(5300, 'Ending Inventory', FALSE, TRUE),
+-- Selling, General and Administrative Expenses
+(7100, 'Advertising Expense', FALSE, TRUE),
+(7200, 'Salaries and Wages', FALSE, TRUE),
+(7300, 'General and Administrative Expenses', FALSE, TRUE),
+(7310, 'Rent Expense', FALSE, TRUE),
+(7320, 'Utilities Expense', FALSE, TRUE),
+(7330, 'Depreciation Expense', FALSE, TRUE),
+-- Non-operating and Extraordinary Items
+(8100, 'Other Income', FALSE, FALSE),
+(8110, 'Interest Income', FALSE, FALSE),
+(8200, 'Other Expenses', FALSE, TRUE),
+(8210, 'Interest Expense', FALSE, TRUE),
+(8300, 'Gain on Sale of Assets', FALSE, FALSE),
+(8400, 'Loss on Sale of Assets', FALSE, TRUE),
+-- Income Taxes
+(9000, 'Income Tax Expense', FALSE, TRUE)
+;
+
+-- names in ja
+insert into account_names(code, lang, name)
+values
+(1110, 'ja', '現金及び現金同等物'),
+(1120, 'ja', '売掛金'),
+(1130, 'ja', '棚卸資産'),
+(1140, 'ja', '前払費用'),
+(1190, 'ja', '仮勘定'),
+(1210, 'ja', '有形固定資産'),
+(1220, 'ja', '無形固定資産'),
+(2100, 'ja', '買掛金'),
+(2110, 'ja', '未払費用'),
+(2120, 'ja', '未払法人税等'),
+(2130, 'ja', '前受収益'),
+(2140, 'ja', '短期借入金'),
+(2200, 'ja', '長期借入金'),
+(3100, 'ja', '普通株式'),
+(3200, 'ja', '資本剰余金'),
+(3300, 'ja', '利益剰余金'),
+(4100, 'ja', '売上高'),
+(4200, 'ja', '製品売上高'),
+(5100, 'ja', '期首棚卸高'),
+(5200, 'ja', '仕入高'),
+(5300, 'ja', '期末棚卸高'),
+(7100, 'ja', '広告宣伝費'),
+(7200, 'ja', '給与手当'),
+(7300, 'ja', '一般管理費'),
+(7310, 'ja', '地代家賃'),
+(7320, 'ja', '水道光熱費'),
+(7330, 'ja', '減価償却費'),
+(8100, 'ja', 'その他の収益'),
+(8110, 'ja', '受取利息'),
+(8200, 'ja', 'その他の費用'),
+(8210, 'ja', '支払利息'),
+(8300, 'ja', '固定資産売却益'),
+(8400, 'ja', '固定資産売却損'),
+(9000, 'ja', '法人税等')
+;
@@ -7,6 +7,7 @@ values
 (1110, '現金及び預金', TRUE, TRUE),
 (1120, '売掛金', TRUE, TRUE),
 (1130, '商品', TRUE, TRUE),
+(1190, '仮勘定', TRUE, TRUE),
 (1210, '有形固定資産', TRUE, TRUE),
 (1211, '機械装置', TRUE, TRUE),
 -- 負債
@@ -18,6 +19,7 @@ values
 -- 純資産
 (3100, '資本金', TRUE, FALSE),
 (3200, '資本剰余金', TRUE, FALSE),
+(3300, '繰越利益剰余金', TRUE, FALSE),
 
 /* P/L科目 */
 -- 売上高
@@ -37,3 +39,33 @@ values
 -- 法人税等
 (9000, '法人税等', FALSE, TRUE)
 ;
+
+-- names in en
+insert into account_names(code, lang, name)
+values
+(1110, 'en', 'Cash and Deposits'),
+(1120, 'en', 'Accounts Receivable'),
+(1130, 'en', 'Merchandise'),
+(1190, 'en', 'Suspense'),
+(1210, 'en', 'Tangible Fixed Assets'),
+(1211, 'en', 'Machinery and Equipment'),
+(2100, 'en', 'Accounts Payable'),
+(2101, 'en', 'Short-term Borrowings'),
+(2102, 'en', 'Income Taxes Payable'),
+(2103, 'en', 'Deposits Received'),
+(2200, 'en', 'Long-term Borrowings'),
+(3100, 'en', 'Capital Stock'),
+(3200, 'en', 'Capital Surplus'),
+(3300, 'en', 'Retained Earnings'),
+(4100, 'en', 'Merchandise Sales'),
+(5100, 'en', 'Beginning Merchandise Inventory'),
+(5200, 'en', 'Merchandise Purchases'),
+(5300, 'en', 'Ending Merchandise Inventory'),
+(7200, 'en', 'Salaries and Bonuses'),
+(7300, 'en', 'Expenses'),
+(8100, 'en', 'Non-operating Income'),
+(8200, 'en', 'Non-operating Expenses'),
+(8300, 'en', 'Extraordinary Gains'),
+(8400, 'en', 'Extraordinary Losses'),
+(9000, 'en', 'Income Taxes')
+;
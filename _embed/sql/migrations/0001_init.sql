@@ -1,15 +1,13 @@
 -- SQLite3
 
-drop table if exists accounts;
-create table accounts(
+create table if not exists accounts(
     code integer not null primary key,
     name text,
     is_bs boolean,
     is_left boolean
 );
 
-drop table if exists journals;
-create table journals(
+create table if not exists journals(
     id integer not null primary key,
     date date,
     code integer,
@@ -17,5 +15,3 @@ create table journals(
     left integer DEFAULT 0,
     right integer DEFAULT 0
 );
-
-
@@ -0,0 +1,31 @@
+-- SQLite3
+
+create table transactions(
+    id integer not null primary key,
+    date date,
+    memo text,
+    reverses_id integer references transactions(id),
+    created_at datetime
+);
+
+alter table journals add column transaction_id integer references transactions(id);
+
+-- journals posted before entries have no record of which journals were posted together.
+-- As a heuristic, journals of the same date are grouped into an entry, which keeps each entry balanced
+-- as long as each day was posted in balance, but merges unrelated postings of the same day into one entry.
+insert into transactions(date, memo, created_at)
+select distinct date, '', CURRENT_TIMESTAMP from journals where date is not null order by date;
+
+update journals set transaction_id = (select t.id from transactions as t where t.date = journals.date)
+where date is not null;
+
+-- journals without a date cannot be grouped by date, so each of them gets an entry of its own,
+-- numbered after the dated entries in the order of journal IDs.
+insert into transactions(id, date, memo, created_at)
+select (select count(distinct date) from journals) + (select count(*) from journals as j2 where j2.date is null and j2.id <= j.id),
+    null, '', CURRENT_TIMESTAMP
+from journals as j where j.date is null order by j.id;
+
+update journals set transaction_id = (select count(distinct date) from journals)
+    + (select count(*) from journals as j2 where j2.date is null and j2.id <= journals.id)
+where date is null;
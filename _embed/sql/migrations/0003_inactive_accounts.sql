@@ -0,0 +1,3 @@
+-- SQLite3
+
+alter table accounts add column inactive boolean DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- SQLite3
+
+alter table journals add column currency text DEFAULT 'JPY';
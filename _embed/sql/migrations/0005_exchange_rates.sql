@@ -0,0 +1,14 @@
+-- SQLite3
+
+alter table journals add column func_left integer DEFAULT 0;
+alter table journals add column func_right integer DEFAULT 0;
+
+update journals set func_left = left, func_right = right where currency = 'JPY';
+
+create table exchange_rates(
+    base text not null,
+    quote text not null,
+    date date not null,
+    rate text not null,
+    primary key (base, quote, date)
+);
@@ -0,0 +1,8 @@
+-- SQLite3
+
+alter table transactions add column closing boolean DEFAULT FALSE;
+
+create table settings(
+    key text not null primary key,
+    value text
+);
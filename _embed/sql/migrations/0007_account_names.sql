@@ -0,0 +1,8 @@
+-- SQLite3
+
+create table account_names(
+    code integer not null references accounts(code),
+    lang text not null,
+    name text not null,
+    primary key (code, lang)
+);
@@ -0,0 +1,12 @@
+-- SQLite3
+
+create table bank_reviews(
+    id integer primary key,
+    transaction_id integer not null references transactions(id),
+    code integer not null references accounts(code),
+    suspense integer not null references accounts(code),
+    date date not null,
+    payee text not null,
+    amount integer not null,
+    resolved_transaction_id integer references transactions(id)
+);
@@ -0,0 +1,29 @@
+-- SQLite3
+
+alter table journals add column reconciled boolean DEFAULT FALSE;
+alter table journals add column statement_ref text DEFAULT '';
+
+-- reconciled journals and their entries are immutable
+create trigger journals_reconciled_update before update on journals
+when old.reconciled
+begin
+    select raise(abort, 'journal is reconciled');
+end;
+
+create trigger journals_reconciled_delete before delete on journals
+when old.reconciled
+begin
+    select raise(abort, 'journal is reconciled');
+end;
+
+create trigger transactions_reconciled_update before update on transactions
+when exists (select 1 from journals where transaction_id = old.id and reconciled)
+begin
+    select raise(abort, 'entry has reconciled journals');
+end;
+
+create trigger transactions_reconciled_delete before delete on transactions
+when exists (select 1 from journals where transaction_id = old.id and reconciled)
+begin
+    select raise(abort, 'entry has reconciled journals');
+end;
@@ -0,0 +1,23 @@
+-- SQLite3
+
+create table customers(
+    id integer primary key,
+    name text not null unique
+);
+
+create table invoices(
+    id integer primary key,
+    customer_id integer not null references customers(id),
+    transaction_id integer not null references transactions(id),
+    date date not null,
+    amount integer not null,
+    memo text DEFAULT ''
+);
+
+create table invoice_payments(
+    id integer primary key,
+    invoice_id integer not null references invoices(id),
+    transaction_id integer not null references transactions(id),
+    date date not null,
+    amount integer not null
+);
@@ -0,0 +1,12 @@
+-- SQLite3
+
+-- suspense account of bank statement lines without matching rule, for books created before the account templates had it.
+-- books without accounts_lang setting are Japanese, which was the only chart of accounts.
+insert into accounts(code, name, is_bs, is_left)
+select 1190, case when COALESCE((select value from settings where key = 'accounts_lang'), 'ja') = 'ja' then '仮勘定' else 'Suspense' end, TRUE, TRUE
+where exists (select 1 from accounts) and not exists (select 1 from accounts where code = 1190);
+
+insert or ignore into account_names(code, lang, name)
+select 1190, case when COALESCE((select value from settings where key = 'accounts_lang'), 'ja') = 'ja' then 'en' else 'ja' end,
+    case when COALESCE((select value from settings where key = 'accounts_lang'), 'ja') = 'ja' then 'Suspense' else '仮勘定' end
+where exists (select 1 from accounts where code = 1190);
@@ -0,0 +1,345 @@
+package bookkeeping
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// ReceivableCode is the accounts receivable account, which the invoices and payments of customers post to.
+	ReceivableCode = 1120
+	// DefaultSalesCode is the account credited by invoices.
+	DefaultSalesCode = 4100
+	// DefaultCashCode is the account debited by payments.
+	DefaultCashCode = 1110
+)
+
+// AddCustomer adds a customer of the name and returns the ID of the customer.
+func (bk *Bookkeeping) AddCustomer(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("customer name is required")
+	}
+
+	cs, err := bk.dbCs.Fetch(DBCustomersFetchOption{Name: name})
+	if err != nil {
+		return 0, err
+	}
+	if len(cs) > 0 {
+		return 0, fmt.Errorf("customer '%s' already exists", name)
+	}
+
+	ids, err := bk.dbCs.Insert(Customer{Name: name})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// FetchCustomers returns all of the customers ordered by ID.
+func (bk *Bookkeeping) FetchCustomers() ([]Customer, error) {
+	return bk.dbCs.Fetch(DBCustomersFetchOption{})
+}
+
+func (bk *Bookkeeping) fetchCustomer(id int) (Customer, error) {
+	cs, err := bk.dbCs.Fetch(DBCustomersFetchOption{ID: []int{id}})
+	if err != nil {
+		return Customer{}, err
+	}
+	if len(cs) != 1 {
+		return Customer{}, fmt.Errorf("customer '%d' is not found", id)
+	}
+	return cs[0], nil
+}
+
+// checkReceivableEntry returns an error if the entry is an invoice or a payment of the accounts receivable subledger,
+// which cannot be reversed without the subledger going out of balance with the receivable account.
+func (bk *Bookkeeping) checkReceivableEntry(entryID int) error {
+	invs, err := bk.dbIn.Fetch(DBInvoicesFetchOption{EntryID: entryID})
+	if err != nil {
+		return err
+	}
+	if len(invs) > 0 {
+		return fmt.Errorf("entry '%d' is invoice '%d' of accounts receivable and cannot be reversed", entryID, invs[0].ID)
+	}
+
+	ps, err := bk.dbIn.FetchPayments(DBInvoicePaymentsFetchOption{EntryID: entryID})
+	if err != nil {
+		return err
+	}
+	if len(ps) > 0 {
+		return fmt.Errorf("entry '%d' is a payment applied to invoice '%d' of accounts receivable and cannot be reversed", entryID, ps[0].InvoiceID)
+	}
+	return nil
+}
+
+type IssueInvoiceOpts struct {
+	CustomerID int
+	Date       time.Time
+	Amount     int
+	Memo       string
+	// SalesCode is the account credited by the invoice. Default is DefaultSalesCode.
+	SalesCode int
+}
+
+// IssueInvoice posts an entry debiting accounts receivable and crediting sales in the same way as Post,
+// and returns the ID of the invoice.
+func (bk *Bookkeeping) IssueInvoice(opt IssueInvoiceOpts) (int, error) {
+	c, err := bk.fetchCustomer(opt.CustomerID)
+	if err != nil {
+		return 0, err
+	}
+	if opt.Amount <= 0 {
+		return 0, fmt.Errorf("invoice amount must be positive, but got %d", opt.Amount)
+	}
+	if opt.SalesCode == 0 {
+		opt.SalesCode = DefaultSalesCode
+	}
+
+	d := sql.NullTime{Time: truncateDay(opt.Date), Valid: true}
+	memo := "Invoice to " + c.Name
+	if opt.Memo != "" {
+		memo += ": " + opt.Memo
+	}
+	e := Entry{
+		Date: d,
+		Memo: memo,
+		Journals: []Journal{
+			{Code: ReceivableCode, Left: opt.Amount, Description: c.Name},
+			{Code: opt.SalesCode, Right: opt.Amount, Description: c.Name},
+		},
+	}
+	if err := bk.validateEntry(&e); err != nil {
+		return 0, err
+	}
+
+	return bk.dbIn.Insert(Invoice{CustomerID: c.ID, Date: d, Amount: opt.Amount, Memo: opt.Memo}, e)
+}
+
+type FetchInvoicesOpts struct {
+	CustomerID int
+	// Open fetches only the invoices not fully paid.
+	Open bool
+}
+
+// FetchInvoices returns invoices with the paid amounts, ordered by date.
+func (bk *Bookkeeping) FetchInvoices(opt FetchInvoicesOpts) ([]Invoice, error) {
+	invs, err := bk.dbIn.Fetch(DBInvoicesFetchOption{CustomerID: opt.CustomerID})
+	if err != nil {
+		return nil, err
+	}
+	if !opt.Open {
+		return invs, nil
+	}
+
+	open := []Invoice{}
+	for _, inv := range invs {
+		if inv.Outstanding() > 0 {
+			open = append(open, inv)
+		}
+	}
+	return open, nil
+}
+
+// PaymentApplication is the amount of a payment applied to an invoice.
+type PaymentApplication struct {
+	InvoiceID int
+	Amount    int
+}
+
+type ReceivePaymentOpts struct {
+	CustomerID int
+	Date       time.Time
+	Amount     int
+	// CashCode is the account debited by the payment. Default is DefaultCashCode.
+	CashCode int
+	// Applications are the invoices the payment is applied to, which must sum up to Amount.
+	// If empty, the payment is applied to the open invoices of the customer from the oldest.
+	Applications []PaymentApplication
+}
+
+// ReceivePayment posts an entry debiting cash and crediting accounts receivable in the same way as Post,
+// applies the payment to the invoices of the customer, and returns the ID of the entry.
+// The payment cannot exceed the outstanding amounts of the invoices.
+func (bk *Bookkeeping) ReceivePayment(opt ReceivePaymentOpts) (int, error) {
+	c, err := bk.fetchCustomer(opt.CustomerID)
+	if err != nil {
+		return 0, err
+	}
+	if opt.Amount <= 0 {
+		return 0, fmt.Errorf("payment amount must be positive, but got %d", opt.Amount)
+	}
+	if opt.CashCode == 0 {
+		opt.CashCode = DefaultCashCode
+	}
+	// invoices issued on the payment date can be paid
+	opt.Date = truncateDay(opt.Date)
+
+	invs, err := bk.dbIn.Fetch(DBInvoicesFetchOption{CustomerID: c.ID})
+	if err != nil {
+		return 0, err
+	}
+
+	apps := opt.Applications
+	if len(apps) == 0 {
+		rest := opt.Amount
+		for _, inv := range invs {
+			if rest == 0 {
+				break
+			}
+			if inv.Outstanding() <= 0 || truncateDay(inv.Date.Time).After(opt.Date) {
+				continue
+			}
+			amount := inv.Outstanding()
+			if amount > rest {
+				amount = rest
+			}
+			apps = append(apps, PaymentApplication{InvoiceID: inv.ID, Amount: amount})
+			rest -= amount
+		}
+		if rest > 0 {
+			return 0, fmt.Errorf("payment %d exceeds the outstanding amount of the invoices of customer '%s' by %d",
+				opt.Amount, c.Name, rest)
+		}
+	}
+
+	byID := make(map[int]*Invoice, len(invs))
+	for i := range invs {
+		byID[invs[i].ID] = &invs[i]
+	}
+
+	d := sql.NullTime{Time: opt.Date, Valid: true}
+	payments := make([]InvoicePayment, 0, len(apps))
+	ids := make([]string, 0, len(apps))
+	total := 0
+	for _, a := range apps {
+		inv, ok := byID[a.InvoiceID]
+		if !ok {
+			return 0, fmt.Errorf("invoice '%d' of customer '%s' is not found", a.InvoiceID, c.Name)
+		}
+		if a.Amount <= 0 {
+			return 0, fmt.Errorf("amount applied to invoice '%d' must be positive, but got %d", a.InvoiceID, a.Amount)
+		}
+		if a.Amount > inv.Outstanding() {
+			return 0, fmt.Errorf("amount %d applied to invoice '%d' exceeds its outstanding amount %d", a.Amount, a.InvoiceID, inv.Outstanding())
+		}
+		if truncateDay(inv.Date.Time).After(opt.Date) {
+			return 0, fmt.Errorf("invoice '%d' is issued after the payment date", a.InvoiceID)
+		}
+		inv.Paid += a.Amount
+		total += a.Amount
+
+		payments = append(payments, InvoicePayment{InvoiceID: a.InvoiceID, Date: d, Amount: a.Amount})
+		ids = append(ids, fmt.Sprint(a.InvoiceID))
+	}
+	if total != opt.Amount {
+		return 0, fmt.Errorf("applied amounts %d must sum up to the payment %d", total, opt.Amount)
+	}
+
+	e := Entry{
+		Date: d,
+		Memo: fmt.Sprintf("Payment from %s for invoice %s", c.Name, strings.Join(ids, ", ")),
+		Journals: []Journal{
+			{Code: opt.CashCode, Left: opt.Amount, Description: c.Name},
+			{Code: ReceivableCode, Right: opt.Amount, Description: c.Name},
+		},
+	}
+	if err := bk.validateEntry(&e); err != nil {
+		return 0, err
+	}
+
+	return bk.dbIn.InsertPayments(e, payments)
+}
+
+// AgingBuckets are the upper bounds of days since the invoice date of the aging buckets except the last one,
+// which is for the older invoices.
+var AgingBuckets = []int{30, 60, 90}
+
+// AgingBucketNames are the names of the aging buckets.
+var AgingBucketNames = []string{"0-30", "31-60", "61-90", "90+"}
+
+// CustomerAging is the outstanding amounts of a customer in the aging buckets.
+type CustomerAging struct {
+	Customer Customer `json:"customer"`
+	Buckets  []int    `json:"buckets"`
+	Total    int      `json:"total"`
+}
+
+// ARAging is the accounts receivable aging of the customers with outstanding invoices on a date.
+type ARAging struct {
+	Date      sql.NullTime    `json:"date"`
+	Buckets   []string        `json:"buckets"`
+	Customers []CustomerAging `json:"customers"`
+	Total     CustomerAging   `json:"total"`
+}
+
+func (a ARAging) MarshalJSON() ([]byte, error) {
+	type arAging ARAging
+	return json.Marshal(struct {
+		arAging
+		Date *string `json:"date"`
+	}{arAging(a), jsonDate(a.Date)})
+}
+
+// agingBucket returns the index of the aging bucket of the days since the invoice date.
+func agingBucket(days int) int {
+	for i, max := range AgingBuckets {
+		if days <= max {
+			return i
+		}
+	}
+	return len(AgingBuckets)
+}
+
+// FetchARAging returns the amounts of the invoices outstanding on the date per customer,
+// bucketed by days since the invoice date.
+func (bk *Bookkeeping) FetchARAging(date time.Time) (ARAging, error) {
+	date = truncateDay(date)
+	invs, err := bk.dbIn.Fetch(DBInvoicesFetchOption{Before: sql.NullTime{Time: endOfDay(date), Valid: true}})
+	if err != nil {
+		return ARAging{}, err
+	}
+	cs, err := bk.FetchCustomers()
+	if err != nil {
+		return ARAging{}, err
+	}
+
+	a := ARAging{
+		Date:      sql.NullTime{Time: date, Valid: true},
+		Buckets:   AgingBucketNames,
+		Customers: []CustomerAging{},
+		Total:     CustomerAging{Buckets: make([]int, len(AgingBucketNames))},
+	}
+
+	byID := make(map[int]*CustomerAging, len(cs))
+	aging := make([]CustomerAging, len(cs))
+	for i, c := range cs {
+		aging[i] = CustomerAging{Customer: c, Buckets: make([]int, len(AgingBucketNames))}
+		byID[c.ID] = &aging[i]
+	}
+
+	for _, inv := range invs {
+		amount := inv.Outstanding()
+		if amount == 0 {
+			continue
+		}
+		days := int(date.Sub(truncateDay(inv.Date.Time)).Hours() / 24)
+		b := agingBucket(days)
+
+		ca := byID[inv.CustomerID]
+		ca.Buckets[b] += amount
+		ca.Total += amount
+		a.Total.Buckets[b] += amount
+		a.Total.Total += amount
+	}
+
+	for _, ca := range aging {
+		if ca.Total != 0 {
+			a.Customers = append(a.Customers, ca)
+		}
+	}
+	return a, nil
+}
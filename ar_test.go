@@ -0,0 +1,224 @@
+package bookkeeping_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_ReceivePayment(t *testing.T) {
+	tdb := newTemplateTestDB(t)
+	bk := bookkeeping.NewBookkeeping(tdb)
+
+	acme, err := bk.AddCustomer("Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bk.AddCustomer("Acme"); err == nil {
+		t.Errorf("AddCustomer() must fail for a duplicate name")
+	}
+
+	inv1, err := bk.IssueInvoice(bookkeeping.IssueInvoiceOpts{CustomerID: acme, Date: date(2021, 4, 1).Time, Amount: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv2, err := bk.IssueInvoice(bookkeeping.IssueInvoiceOpts{CustomerID: acme, Date: date(2021, 4, 15).Time, Amount: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// applied to the specific invoice
+	if _, err := bk.ReceivePayment(bookkeeping.ReceivePaymentOpts{
+		CustomerID: acme, Date: date(2021, 4, 20).Time, Amount: 30000,
+		Applications: []bookkeeping.PaymentApplication{{InvoiceID: inv2, Amount: 30000}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// applied from the oldest invoice
+	if _, err := bk.ReceivePayment(bookkeeping.ReceivePaymentOpts{CustomerID: acme, Date: date(2021, 4, 30).Time, Amount: 110000}); err != nil {
+		t.Fatal(err)
+	}
+
+	invs, err := bk.FetchInvoices(bookkeeping.FetchInvoicesOpts{CustomerID: acme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]int{inv1: 0, inv2: 10000}
+	for _, inv := range invs {
+		if inv.Outstanding() != want[inv.ID] {
+			t.Errorf("invoice %d outstanding must be %d, but got %d", inv.ID, want[inv.ID], inv.Outstanding())
+		}
+	}
+
+	tests := []struct {
+		name string
+		opt  bookkeeping.ReceivePaymentOpts
+	}{
+		{"exceeds outstanding", bookkeeping.ReceivePaymentOpts{CustomerID: acme, Date: date(2021, 5, 1).Time, Amount: 20000}},
+		{"exceeds the invoice", bookkeeping.ReceivePaymentOpts{CustomerID: acme, Date: date(2021, 5, 1).Time, Amount: 20000,
+			Applications: []bookkeeping.PaymentApplication{{InvoiceID: inv1, Amount: 20000}}}},
+		{"applications do not sum up", bookkeeping.ReceivePaymentOpts{CustomerID: acme, Date: date(2021, 5, 1).Time, Amount: 10000,
+			Applications: []bookkeeping.PaymentApplication{{InvoiceID: inv2, Amount: 5000}}}},
+		{"unknown customer", bookkeeping.ReceivePaymentOpts{CustomerID: 99, Date: date(2021, 5, 1).Time, Amount: 10000}},
+	}
+	for _, tt := range tests {
+		if _, err := bk.ReceivePayment(tt.opt); err == nil {
+			t.Errorf("ReceivePayment() must fail: %s", tt.name)
+		}
+	}
+
+	// accounts receivable agrees with the invoices
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1110, 1120, 4100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ledgerOf(gl, 1120).Closing; got != 10000 {
+		t.Errorf("code 1120 closing balance must be 10000, but got %v", got)
+	}
+	if got := ledgerOf(gl, 1110).Closing; got != 140000 {
+		t.Errorf("code 1110 closing balance must be 140000, but got %v", got)
+	}
+	if got := ledgerOf(gl, 4100).Closing; got != 150000 {
+		t.Errorf("code 4100 closing balance must be 150000, but got %v", got)
+	}
+}
+
+func Test_FetchARAging(t *testing.T) {
+	tdb := newTemplateTestDB(t)
+	bk := bookkeeping.NewBookkeeping(tdb)
+
+	acme, err := bk.AddCustomer("Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, err := bk.AddCustomer("Beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bk.AddCustomer("Gamma"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, inv := range []bookkeeping.IssueInvoiceOpts{
+		{CustomerID: acme, Date: date(2021, 6, 30).Time, Amount: 1000},  // 0 days
+		{CustomerID: acme, Date: date(2021, 5, 31).Time, Amount: 2000},  // 30 days
+		{CustomerID: acme, Date: date(2021, 5, 30).Time, Amount: 3000},  // 31 days
+		{CustomerID: beta, Date: date(2021, 4, 1).Time, Amount: 4000},   // 90 days
+		{CustomerID: beta, Date: date(2021, 3, 31).Time, Amount: 5000},  // 91 days
+		{CustomerID: beta, Date: date(2021, 7, 1).Time, Amount: 100000}, // after the date
+	} {
+		if _, err := bk.IssueInvoice(inv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// paid after the date, so outstanding on the date
+	if _, err := bk.ReceivePayment(bookkeeping.ReceivePaymentOpts{CustomerID: beta, Date: date(2021, 7, 2).Time, Amount: 5000}); err != nil {
+		t.Fatal(err)
+	}
+	// paid on the date, applied to the oldest invoice
+	if _, err := bk.ReceivePayment(bookkeeping.ReceivePaymentOpts{CustomerID: acme, Date: date(2021, 6, 30).Time, Amount: 500}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := bk.FetchARAging(date(2021, 6, 30).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]int{
+		"Acme": {3000, 2500, 0, 0},
+		"Beta": {0, 0, 4000, 5000},
+	}
+	if len(a.Customers) != len(want) {
+		t.Fatalf("FetchARAging() must return %d customers, but got %d", len(want), len(a.Customers))
+	}
+	for _, ca := range a.Customers {
+		for i, v := range want[ca.Customer.Name] {
+			if ca.Buckets[i] != v {
+				t.Errorf("customer %s bucket %s must be %d, but got %d", ca.Customer.Name, a.Buckets[i], v, ca.Buckets[i])
+			}
+		}
+	}
+	if a.Total.Total != 14500 {
+		t.Errorf("FetchARAging() total must be 14500, but got %d", a.Total.Total)
+	}
+}
+
+func Test_Reverse_Receivable(t *testing.T) {
+	tdb := newTemplateTestDB(t)
+	bk := bookkeeping.NewBookkeeping(tdb)
+
+	acme, err := bk.AddCustomer("Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv, err := bk.IssueInvoice(bookkeeping.IssueInvoiceOpts{CustomerID: acme, Date: date(2021, 4, 1).Time, Amount: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payment, err := bk.ReceivePayment(bookkeeping.ReceivePaymentOpts{CustomerID: acme, Date: date(2021, 4, 20).Time, Amount: 40000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	invs, err := bk.FetchInvoices(bookkeeping.FetchInvoicesOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invs) != 1 || invs[0].ID != inv {
+		t.Fatalf("FetchInvoices() must return invoice %d, but got %v", inv, invs)
+	}
+
+	// entries of the subledger cannot be reversed in the general ledger
+	for _, id := range []int{invs[0].EntryID, payment} {
+		if _, err := bk.Reverse(id, date(2021, 4, 30).Time); err == nil {
+			t.Errorf("Reverse() must fail for entry %d of accounts receivable", id)
+		}
+	}
+
+	a, err := bk.FetchARAging(date(2021, 4, 30).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{bookkeeping.ReceivableCode}, End: date(2021, 4, 30).Time})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ledgerOf(gl, bookkeeping.ReceivableCode).Closing; a.Total.Total != got || got != 60000 {
+		t.Errorf("subledger total %v must equal code 1120 balance %v, which is 60000", a.Total.Total, got)
+	}
+}
+
+func Test_ReceivePayment_SameDay(t *testing.T) {
+	tdb := newTemplateTestDB(t)
+	bk := bookkeeping.NewBookkeeping(tdb)
+
+	acme, err := bk.AddCustomer("Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// issued in the afternoon, and paid and aged on the date
+	if _, err := bk.IssueInvoice(bookkeeping.IssueInvoiceOpts{CustomerID: acme, Date: date(2021, 4, 1).Time.Add(15 * time.Hour), Amount: 3000}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := bk.FetchARAging(date(2021, 4, 1).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Total.Total != 3000 || a.Total.Buckets[0] != 3000 {
+		t.Errorf("aging on the invoice date must have 3000 in the first bucket, but got %+v", a.Total)
+	}
+
+	if _, err := bk.ReceivePayment(bookkeeping.ReceivePaymentOpts{CustomerID: acme, Date: date(2021, 4, 1).Time, Amount: 1000}); err != nil {
+		t.Fatalf("ReceivePayment() on the invoice date error = %v", err)
+	}
+
+	a, err = bk.FetchARAging(date(2021, 4, 1).Time.Add(9 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Total.Total != 2000 {
+		t.Errorf("aging on the payment date must be 2000, but got %+v", a.Total)
+	}
+}
@@ -0,0 +1,129 @@
+package bookkeeping
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// BankLine is a line of a bank statement in the functional currency.
+type BankLine struct {
+	Date  time.Time
+	Payee string
+	// Amount is positive for deposits and negative for withdrawals.
+	Amount int
+	// Contra is the account of the other side of the bank account, or 0 if unknown.
+	Contra int
+}
+
+// ImportBankLines posts an entry between the bank account of the code and the contra account for each line,
+// and returns the IDs of the posted entries.
+// Lines without contra account are posted to the suspense account and queued for review.
+// If any of the lines is invalid, none of them is posted and *EntryError is returned.
+func (bk *Bookkeeping) ImportBankLines(code, suspense int, lines []BankLine) ([]int, error) {
+	for _, l := range lines {
+		if l.Contra != 0 {
+			continue
+		}
+		// unmatched lines must not fail the import one by one
+		acc, err := bk.fetchAccount(suspense)
+		if err != nil {
+			return nil, fmt.Errorf("suspense account '%d' for lines without contra account is not found", suspense)
+		}
+		if acc.Inactive {
+			return nil, fmt.Errorf("suspense account '%d' for lines without contra account is deactivated", suspense)
+		}
+		break
+	}
+
+	entries := make([]Entry, 0, len(lines))
+	reviews := make([]BankReview, 0, len(lines))
+
+	for i, l := range lines {
+		d := sql.NullTime{Time: l.Date, Valid: true}
+		rv := BankReview{}
+		contra := l.Contra
+		if contra == 0 {
+			contra = suspense
+			rv = BankReview{Code: code, Suspense: suspense, Date: d, Payee: l.Payee, Amount: l.Amount}
+		}
+
+		e := Entry{Date: d, Memo: l.Payee, Journals: bankJournals(code, contra, l.Amount, l.Payee)}
+		if err := bk.validateEntry(&e); err != nil {
+			return nil, &EntryError{Index: i, Err: err}
+		}
+
+		entries = append(entries, e)
+		reviews = append(reviews, rv)
+	}
+
+	return bk.dbBr.Insert(entries, reviews)
+}
+
+// bankJournals returns the journals of a bank statement line, debit to the bank account for deposits.
+func bankJournals(code, contra, amount int, desc string) []Journal {
+	if amount < 0 {
+		return []Journal{
+			{Code: contra, Left: -amount, Description: desc},
+			{Code: code, Right: -amount, Description: desc},
+		}
+	}
+	return []Journal{
+		{Code: code, Left: amount, Description: desc},
+		{Code: contra, Right: amount, Description: desc},
+	}
+}
+
+// checkBankReviewEntry returns an error if the entry is posted from a bank statement line queued for review,
+// or resolves one, which cannot be reversed without leaving the review queue out of balance with the suspense account.
+func (bk *Bookkeeping) checkBankReviewEntry(entryID int) error {
+	rvs, err := bk.dbBr.Fetch(DBBankReviewsFetchOption{EntryID: entryID})
+	if err != nil {
+		return err
+	}
+	if len(rvs) > 0 {
+		return fmt.Errorf("entry '%d' belongs to bank review '%d' and cannot be reversed", entryID, rvs[0].ID)
+	}
+	return nil
+}
+
+type FetchBankReviewsOpts struct {
+	Code int
+	// IncludeResolved includes the reviews already resolved.
+	IncludeResolved bool
+}
+
+// FetchBankReviews returns the bank statement lines queued for review, ordered by date.
+func (bk *Bookkeeping) FetchBankReviews(opt FetchBankReviewsOpts) ([]BankReview, error) {
+	return bk.dbBr.Fetch(DBBankReviewsFetchOption{Code: opt.Code, Unresolved: !opt.IncludeResolved})
+}
+
+// ResolveBankReview posts an entry which moves the amount of the review from the suspense account
+// to the account of the code on the date, and returns the ID of the posted entry.
+// The entry is posted on the date of resolving rather than the date of the line,
+// so that a review of a line in a locked or closed period can still be resolved.
+func (bk *Bookkeeping) ResolveBankReview(id, code int, date time.Time) (int, error) {
+	rvs, err := bk.dbBr.Fetch(DBBankReviewsFetchOption{ID: []int{id}})
+	if err != nil {
+		return 0, err
+	}
+	if len(rvs) == 0 {
+		return 0, fmt.Errorf("bank review '%d' is not found", id)
+	}
+	rv := rvs[0]
+	if rv.ResolvedBy > 0 {
+		return 0, fmt.Errorf("bank review '%d' is already resolved by entry '%d'", id, rv.ResolvedBy)
+	}
+
+	// the suspense account takes the place of the bank account in the journals of the line
+	e := Entry{
+		Date:     sql.NullTime{Time: date, Valid: true},
+		Memo:     fmt.Sprintf("Review of entry %d: %s", rv.EntryID, rv.Payee),
+		Journals: bankJournals(rv.Suspense, code, rv.Amount, rv.Payee),
+	}
+	if err := bk.validateEntry(&e); err != nil {
+		return 0, err
+	}
+
+	return bk.dbBr.Resolve(id, e)
+}
@@ -0,0 +1,154 @@
+package bookkeeping_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_ImportBankLines(t *testing.T) {
+	tdb := newTemplateTestDB(t)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	ids, err := bk.ImportBankLines(1110, 1190, []bookkeeping.BankLine{
+		{Date: date(2021, 4, 1).Time, Payee: "振込 カブシキガイシャエー", Amount: 300000, Contra: 4100},
+		{Date: date(2021, 4, 2).Time, Payee: "JR東日本", Amount: -1200, Contra: 7300},
+		{Date: date(2021, 4, 3).Time, Payee: "カード引落", Amount: -5000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("ImportBankLines() must post 3 entries, but got %v", len(ids))
+	}
+
+	reviews, err := bk.FetchBankReviews(bookkeeping.FetchBankReviewsOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reviews) != 1 {
+		t.Fatalf("FetchBankReviews() must return 1 review, but got %v", len(reviews))
+	}
+	rv := reviews[0]
+	if rv.EntryID != ids[2] || rv.Amount != -5000 || rv.Payee != "カード引落" || rv.Suspense != 1190 {
+		t.Errorf("FetchBankReviews() got unexpected review %+v", rv)
+	}
+
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1110, 1190, 7300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ledgerOf(gl, 1110).Closing; got != 293800 {
+		t.Errorf("code 1110 closing balance must be 293800, but got %v", got)
+	}
+	if got := ledgerOf(gl, 1190).Closing; got != 5000 {
+		t.Errorf("code 1190 closing balance must be 5000, but got %v", got)
+	}
+
+	// the entry queued for review cannot be reversed
+	if _, err := bk.Reverse(ids[2], date(2021, 4, 5).Time); err == nil {
+		t.Errorf("Reverse() must fail for the entry of a bank review")
+	}
+
+	// the review of a line in a locked period is resolved after the period
+	if err := bk.Lock(date(2021, 4, 30).Time); err != nil {
+		t.Fatal(err)
+	}
+	var lockedErr *bookkeeping.LockedError
+	if _, err := bk.ResolveBankReview(rv.ID, 7300, date(2021, 4, 30).Time); !errors.As(err, &lockedErr) {
+		t.Errorf("ResolveBankReview() on a locked date must return *LockedError, but got %v", err)
+	}
+	id, err := bk.ResolveBankReview(rv.ID, 7300, date(2021, 5, 1).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := bk.FetchEntry(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Date.Time.Equal(date(2021, 5, 1).Time) {
+		t.Errorf("resolving entry must be on the resolution date, but got %v", entry.Date.Time)
+	}
+
+	gl, err = bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1190, 7300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ledgerOf(gl, 1190).Closing; got != 0 {
+		t.Errorf("code 1190 closing balance must be 0 after resolve, but got %v", got)
+	}
+	if got := ledgerOf(gl, 7300).Closing; got != 6200 {
+		t.Errorf("code 7300 closing balance must be 6200, but got %v", got)
+	}
+
+	reviews, err = bk.FetchBankReviews(bookkeeping.FetchBankReviewsOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reviews) != 0 {
+		t.Errorf("FetchBankReviews() must return no unresolved review, but got %v", len(reviews))
+	}
+	if _, err := bk.ResolveBankReview(rv.ID, 7300, date(2021, 5, 1).Time); err == nil {
+		t.Errorf("ResolveBankReview() must fail for a resolved review")
+	}
+	if _, err := bk.Reverse(id, date(2021, 5, 5).Time); err == nil {
+		t.Errorf("Reverse() must fail for the entry resolving a bank review")
+	}
+}
+
+func Test_ImportBankLines_Invalid(t *testing.T) {
+	tdb := newTemplateTestDB(t)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	_, err := bk.ImportBankLines(1110, 1190, []bookkeeping.BankLine{
+		{Date: date(2021, 4, 1).Time, Payee: "A", Amount: 1000, Contra: 4100},
+		{Date: date(2021, 4, 2).Time, Payee: "B", Amount: -1000, Contra: 9999},
+	})
+	var entryErr *bookkeeping.EntryError
+	if !errors.As(err, &entryErr) || entryErr.Index != 1 {
+		t.Fatalf("ImportBankLines() must fail with EntryError at index 1, but got %v", err)
+	}
+
+	entries, err := bk.FetchEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("ImportBankLines() must post no entries on error, but got %v", len(entries))
+	}
+}
+
+func Test_ImportBankLines_NoSuspense(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	_, err := bk.ImportBankLines(1110, 1190, []bookkeeping.BankLine{
+		{Date: date(2021, 4, 1).Time, Payee: "A", Amount: 1000, Contra: 4100},
+		{Date: date(2021, 4, 2).Time, Payee: "B", Amount: -1000},
+	})
+	if err == nil || !strings.Contains(err.Error(), "suspense account '1190'") {
+		t.Errorf("ImportBankLines() must fail for the missing suspense account, but got %v", err)
+	}
+
+	// all of the lines are matched, so the suspense account is not required
+	if _, err := bk.ImportBankLines(1110, 1190, []bookkeeping.BankLine{
+		{Date: date(2021, 4, 1).Time, Payee: "A", Amount: 1000, Contra: 4100},
+	}); err != nil {
+		t.Errorf("ImportBankLines() error = %v", err)
+	}
+}
+
+// newTemplateTestDB returns a database with the accounts of the default template, which has the suspense account.
+func newTemplateTestDB(t *testing.T) *bookkeeping.DB {
+	t.Helper()
+
+	tdb, err := bookkeeping.CreateDB(":memory:", bookkeeping.DefaultAccountTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tdb.Close() })
+	return tdb
+}
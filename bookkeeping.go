@@ -2,45 +2,236 @@ package bookkeeping
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Bookkeeping struct {
 	db   *DB
+	dbEn *DBEntries
 	dbJn *DBJournals
 	dbAc *DBAccounts
+	dbFx *DBExchangeRates
+	dbSt *DBSettings
+	dbBr *DBBankReviews
+	dbCs *DBCustomers
+	dbIn *DBInvoices
+
+	// lang is the language of names set by SetLang.
+	lang string
 }
 
 func NewBookkeeping(db *DB) *Bookkeeping {
 	return &Bookkeeping{
 		db:   db,
+		dbEn: NewDBEntries(db),
 		dbJn: NewDBJournals(db),
 		dbAc: NewDBAccounts(db),
+		dbFx: NewDBExchangeRates(db),
+		dbSt: NewDBSettings(db),
+		dbBr: NewDBBankReviews(db),
+		dbCs: NewDBCustomers(db),
+		dbIn: NewDBInvoices(db),
 	}
 }
 
+// Post posts journals as a single entry.
 func (bk *Bookkeeping) Post(jn []Journal) error {
-	if err := balance(jn); err != nil {
-		return fmt.Errorf("journals are not balancing: %w", err)
+	_, err := bk.PostEntry(Entry{Journals: jn})
+	return err
+}
+
+// PostEntry posts an entry and returns the ID of the posted entry.
+// If the entry date is not set, the date of the first journal is used.
+func (bk *Bookkeeping) PostEntry(e Entry) (int, error) {
+	// validation fills in the journals, which must not change the journals of the caller
+	e.Journals = append([]Journal(nil), e.Journals...)
+	if err := bk.validateEntry(&e); err != nil {
+		return 0, err
+	}
+
+	ids, err := bk.dbEn.Insert(e)
+	if err != nil {
+		return 0, err
+	}
+
+	return ids[0], nil
+}
+
+// CheckEntry validates the entry in the same way as PostEntry, without posting it.
+func (bk *Bookkeeping) CheckEntry(e Entry) error {
+	e.Journals = append([]Journal(nil), e.Journals...)
+	return bk.validateEntry(&e)
+}
+
+// Reverse posts an entry which mirrors the entry of entryID, with debits and credits swapped,
+// on the date, and returns the ID of the reversing entry.
+// The original entry is kept as is, so that both entries remain in the general ledger.
+func (bk *Bookkeeping) Reverse(entryID int, date time.Time) (int, error) {
+	orig, err := bk.FetchEntry(entryID)
+	if err != nil {
+		return 0, err
+	}
+
+	if orig.ReversedBy > 0 {
+		return 0, fmt.Errorf("entry '%d' is already reversed by entry '%d'", orig.ID, orig.ReversedBy)
+	}
+	if orig.ReversalOf > 0 {
+		return 0, fmt.Errorf("entry '%d' is a reversal of entry '%d' and cannot be reversed", orig.ID, orig.ReversalOf)
+	}
+	if orig.Closing {
+		return 0, fmt.Errorf("entry '%d' is a closing entry and cannot be reversed", orig.ID)
+	}
+	if err := bk.checkReceivableEntry(orig.ID); err != nil {
+		return 0, err
+	}
+	if err := bk.checkBankReviewEntry(orig.ID); err != nil {
+		return 0, err
+	}
+
+	d := sql.NullTime{Time: date, Valid: true}
+	rev := Entry{
+		Date:       d,
+		Memo:       fmt.Sprintf("Reversal of entry %d", orig.ID),
+		ReversalOf: orig.ID,
+		Journals:   make([]Journal, 0, len(orig.Journals)),
+	}
+	if orig.Memo != "" {
+		rev.Memo += ": " + orig.Memo
+	}
+
+	for _, j := range orig.Journals {
+		rev.Journals = append(rev.Journals, Journal{
+			Date:        d,
+			Code:        j.Code,
+			Description: j.Description,
+			Currency:    j.Currency,
+			Left:        j.Right,
+			Right:       j.Left,
+			FuncLeft:    j.FuncRight,
+			FuncRight:   j.FuncLeft,
+		})
+	}
+
+	return bk.postSystemEntry(rev)
+}
+
+// postSystemEntry posts an entry generated from posted journals, such as reversing, closing and revaluation entries,
+// which may post to deactivated accounts to offset their balances.
+func (bk *Bookkeeping) postSystemEntry(e Entry) (int, error) {
+	if err := bk.validateSystemEntry(&e); err != nil {
+		return 0, err
+	}
+
+	ids, err := bk.dbEn.Insert(e)
+	if err != nil {
+		return 0, err
 	}
+	return ids[0], nil
+}
+
+// EntryError is an error of an entry in PostEntries.
+type EntryError struct {
+	// Index is the index of the entry in the posted entries.
+	Index int
+	Err   error
+}
+
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("entry #%d: %v", e.Index+1, e.Err)
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// PostEntries posts all of the entries in a single database transaction, and returns the IDs of the posted entries.
+// If any of the entries is invalid, none of them is posted and *EntryError is returned.
+func (bk *Bookkeeping) PostEntries(entries []Entry) ([]int, error) {
+	entries = append([]Entry(nil), entries...)
+	for i := range entries {
+		entries[i].Journals = append([]Journal(nil), entries[i].Journals...)
+		if err := bk.validateEntry(&entries[i]); err != nil {
+			return nil, &EntryError{Index: i, Err: err}
+		}
+	}
+
+	return bk.dbEn.Insert(entries...)
+}
+
+func (bk *Bookkeeping) validateEntry(e *Entry) error {
+	return bk.checkEntry(e, false)
+}
+
+// validateSystemEntry validates an entry generated from posted journals in the same way as validateEntry,
+// except that journals of deactivated accounts are allowed.
+func (bk *Bookkeeping) validateSystemEntry(e *Entry) error {
+	return bk.checkEntry(e, true)
+}
 
-	for _, j := range jn {
-		if err := bk.validateJournalRecord(j); err != nil {
+func (bk *Bookkeeping) checkEntry(e *Entry, allowInactive bool) error {
+	if len(e.Journals) == 0 {
+		return fmt.Errorf("journals are not balancing: credit or debit is zero-amount")
+	}
+
+	if !e.Date.Valid {
+		e.Date = e.Journals[0].Date
+	}
+	// entries are dated by calendar date
+	e.Date.Time = truncateDay(e.Date.Time)
+
+	if err := bk.checkLock(e.Date.Time); err != nil {
+		return err
+	}
+
+	for i, j := range e.Journals {
+		if j.Date.Valid && !truncateDay(j.Date.Time).Equal(e.Date.Time) {
+			return fmt.Errorf("journals in an entry must have the same date, but got %s and %s",
+				e.Date.Time.Format("2006/01/02"), j.Date.Time.Format("2006/01/02"))
+		}
+		e.Journals[i].Date = e.Date
+
+		if err := bk.validateJournalRecord(j, allowInactive); err != nil {
 			return err
 		}
 	}
 
-	if err := bk.dbJn.Insert(jn...); err != nil {
+	if err := bk.convertEntry(e); err != nil {
 		return err
 	}
 
+	if err := balance(e.Journals); err != nil {
+		return fmt.Errorf("journals are not balancing: %w", err)
+	}
+
 	return nil
 }
 
-func (bk *Bookkeeping) validateJournalRecord(j Journal) error {
+// FetchEntries returns all of the entries with their journals, ordered by entry ID.
+func (bk *Bookkeeping) FetchEntries() ([]Entry, error) {
+	return bk.dbEn.Fetch(DBEntriesFetchOption{Lang: bk.lang})
+}
+
+// FetchEntry returns the entry of the ID with all of its journals.
+func (bk *Bookkeeping) FetchEntry(id int) (Entry, error) {
+	entries, err := bk.dbEn.Fetch(DBEntriesFetchOption{ID: []int{id}, Lang: bk.lang})
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if len(entries) == 0 {
+		return Entry{}, fmt.Errorf("entry '%d' is not found", id)
+	}
+
+	return entries[0], nil
+}
+
+func (bk *Bookkeeping) validateJournalRecord(j Journal, allowInactive bool) error {
 	accs, err := bk.dbAc.Fetch(DBAccountsFetchOption{CodePattern: strconv.Itoa(j.Code)})
 	if err != nil {
 		return err
@@ -55,7 +246,16 @@ func (bk *Bookkeeping) validateJournalRecord(j Journal) error {
 		if j.Right > 0 {
 			norm = "credit"
 		}
-		return fmt.Errorf("code '%d' is not available (in journal %s record '%d/%d%s')", j.Code, norm, j.Code, j.Left+j.Right, desc)
+		amount := Money{Amount: j.Left + j.Right, Currency: journalCurrency(j)}
+		return fmt.Errorf("code '%d' is not available (in journal %s record '%d/%s%s')", j.Code, norm, j.Code, amount, desc)
+	}
+
+	if _, err := CurrencyExponent(journalCurrency(j)); err != nil {
+		return err
+	}
+
+	if accs[0].Inactive && !allowInactive {
+		return fmt.Errorf("code '%d' is deactivated and cannot be used for new journals", j.Code)
 	}
 	return nil
 }
@@ -76,63 +276,399 @@ func (bk *Bookkeeping) FetchAc(opt FetchAcOpts) ([]Account, error) {
 	acFetchOpt := DBAccountsFetchOption{
 		CodePattern:        opt.CodeFilter,
 		DescriptionPattern: opt.DescFilter,
+		Lang:               bk.lang,
 	}
 	return bk.dbAc.Fetch(acFetchOpt)
 }
 
+// accountClasses is the code system of accounts described in README.
+// An account code is 4 digits, and its prefix decides its BS/PL type and normal side.
+var accountClasses = []struct {
+	prefix string
+	isBS   bool
+	isLeft bool
+}{
+	{"1", true, true},    // 資産
+	{"2", true, false},   // 負債
+	{"3", true, false},   // 純資産
+	{"4", false, false},  // 売上高
+	{"5", false, true},   // 売上原価
+	{"6", false, true},   // 製造原価
+	{"7", false, true},   // 販売費及び一般管理費
+	{"81", false, false}, // 営業外収益
+	{"82", false, true},  // 営業外費用
+	{"83", false, false}, // 特別利益
+	{"84", false, true},  // 特別損失
+	{"9", false, true},   // 法人税等
+}
+
+func validateAccountCode(a Account) error {
+	if a.Code < 1000 || a.Code > 9999 {
+		return fmt.Errorf("account code must be 4 digits, but got '%d'", a.Code)
+	}
+
+	code := strconv.Itoa(a.Code)
+	for _, c := range accountClasses {
+		if !strings.HasPrefix(code, c.prefix) {
+			continue
+		}
+
+		if c.isBS != a.IsBS || c.isLeft != a.IsLeft {
+			return fmt.Errorf("account code '%d' must be %s account on %s side", a.Code, bsplName(c.isBS), sideName(c.isLeft))
+		}
+		return nil
+	}
+
+	return fmt.Errorf("account code '%d' is not in any code range", a.Code)
+}
+
+func bsplName(isBS bool) string {
+	if isBS {
+		return "BS"
+	}
+	return "PL"
+}
+
+func sideName(isLeft bool) string {
+	if isLeft {
+		return "debit"
+	}
+	return "credit"
+}
+
+// AddAccount adds a new account after checking that its code is in the code range for its type and normal side.
+func (bk *Bookkeeping) AddAccount(a Account) error {
+	if a.Name == "" {
+		return fmt.Errorf("account name is required")
+	}
+
+	if err := validateAccountCode(a); err != nil {
+		return err
+	}
+
+	accs, err := bk.dbAc.Fetch(DBAccountsFetchOption{CodePattern: strconv.Itoa(a.Code)})
+	if err != nil {
+		return err
+	}
+	if len(accs) > 0 {
+		return fmt.Errorf("account code '%d' already exists as '%s'", a.Code, accs[0].Name)
+	}
+
+	return bk.dbAc.Insert(a)
+}
+
+// RenameAccount changes the name of the account of the code.
+// If the language set by SetLang differs from the language of account names, it changes the localized name.
+func (bk *Bookkeeping) RenameAccount(code int, name string) error {
+	if name == "" {
+		return fmt.Errorf("account name is required")
+	}
+
+	a, err := bk.fetchAccount(code)
+	if err != nil {
+		return err
+	}
+
+	lang, err := bk.AccountsLang()
+	if err != nil {
+		return err
+	}
+	if bk.lang != "" && bk.lang != lang {
+		return bk.dbAc.SetName(code, bk.lang, name)
+	}
+
+	a.Name = name
+	return bk.dbAc.Update(a)
+}
+
+// DeactivateAccount makes the account of the code unavailable for new journals.
+// Journals already posted to the account remain in reports.
+func (bk *Bookkeeping) DeactivateAccount(code int) error {
+	a, err := bk.fetchAccount(code)
+	if err != nil {
+		return err
+	}
+
+	if a.Inactive {
+		return fmt.Errorf("account code '%d' is already deactivated", code)
+	}
+
+	a.Inactive = true
+	return bk.dbAc.Update(a)
+}
+
+func (bk *Bookkeeping) fetchAccount(code int) (Account, error) {
+	accs, err := bk.dbAc.Fetch(DBAccountsFetchOption{CodePattern: strconv.Itoa(code)})
+	if err != nil {
+		return Account{}, err
+	}
+
+	if len(accs) != 1 {
+		return Account{}, fmt.Errorf("account code '%d' is not found", code)
+	}
+	return accs[0], nil
+}
+
 type FetchGLOpts struct {
 	AccountIDList []int
+
+	// Start and End limit the period of the ledger lines.
+	// Journals before Start are summed up into the opening balance.
+	Start time.Time
+	End   time.Time
+
+	// DescFilter, MinAmount and MaxAmount filter journals by description (wildcard '*' supported)
+	// and amount in the currency of the ledgers. Zero MinAmount or MaxAmount means no limit.
+	// The filters limit the lines shown, and the balances are of all of the journals of the accounts.
+	// Only the accounts with lines matching the filters are in the ledgers, unless IncludeEmpty.
+	DescFilter string
+	MinAmount  int
+	MaxAmount  int
+
+	// IncludeEmpty includes ledgers of accounts without any journal in the period.
+	IncludeEmpty bool
+
+	// Currency limits journals to the transaction currency, and the ledgers are in the currency.
+	// Empty currency includes all of the journals, and the ledgers are in the functional currency.
+	Currency string
+}
+
+// Ledger is the general ledger of an account.
+// Opening, Closing and Balance of each line are balances on the normal side of the account in the currency of the ledger.
+type Ledger struct {
+	Account  Account      `json:"account"`
+	Currency string       `json:"currency"`
+	Opening  int          `json:"opening"`
+	Lines    []LedgerLine `json:"lines"`
+	Closing  int          `json:"closing"`
 }
 
-func (bk *Bookkeeping) FetchGL(opts ...FetchGLOpts) (map[int][]Journal, error) {
-	jnFetchOpts := DBJournalsFetchOption{}
+// LedgerLine is a journal in the general ledger with the running balance after it.
+// Debit and Credit are the amounts of the journal in the currency of the ledger.
+type LedgerLine struct {
+	Journal
+
+	Debit   int `json:"ledger_debit"`
+	Credit  int `json:"ledger_credit"`
+	Balance int `json:"balance"`
+}
+
+func (l LedgerLine) MarshalJSON() ([]byte, error) {
+	type journal Journal
+	return json.Marshal(struct {
+		journal
+		Date    *string `json:"date"`
+		Debit   int     `json:"ledger_debit"`
+		Credit  int     `json:"ledger_credit"`
+		Balance int     `json:"balance"`
+	}{journal(l.Journal), jsonDate(l.Date), l.Debit, l.Credit, l.Balance})
+}
+
+// FetchGL returns the ledgers of accounts ordered by account code.
+// Lines of each ledger are ordered by date, then by journal ID.
+// Each line only holds the journal of its account, with the EntryID to fetch the whole entry by FetchEntry.
+func (bk *Bookkeeping) FetchGL(opts ...FetchGLOpts) ([]Ledger, error) {
+	jnFetchOpts := DBJournalsFetchOption{Lang: bk.lang}
+	var start time.Time
+	includeEmpty := false
 	for _, o := range opts {
+		includeEmpty = includeEmpty || o.IncludeEmpty
+		if o.Currency != "" {
+			jnFetchOpts.Currency = o.Currency
+		}
 		jnFetchOpts.Code = append(jnFetchOpts.Code, o.AccountIDList...)
+		if !o.Start.IsZero() {
+			start = o.Start
+		}
+		if !o.End.IsZero() {
+			jnFetchOpts.Before = sql.NullTime{Time: o.End, Valid: true}
+		}
+		if o.DescFilter != "" {
+			jnFetchOpts.DescriptionPattern = o.DescFilter
+		}
+		if o.MinAmount > 0 {
+			jnFetchOpts.MinAmount = sql.NullInt64{Int64: int64(o.MinAmount), Valid: true}
+		}
+		if o.MaxAmount > 0 {
+			jnFetchOpts.MaxAmount = sql.NullInt64{Int64: int64(o.MaxAmount), Valid: true}
+		}
 	}
 	journals, err := bk.dbJn.Fetch(jnFetchOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	res := make(map[int][]Journal)
+	// shown is the IDs of the journals matching the filters, or nil without filters
+	var shown map[int]bool
+	if jnFetchOpts.DescriptionPattern != "" || jnFetchOpts.MinAmount.Valid || jnFetchOpts.MaxAmount.Valid {
+		shown = make(map[int]bool, len(journals))
+		for _, j := range journals {
+			shown[j.ID] = true
+		}
+
+		all := jnFetchOpts
+		all.DescriptionPattern, all.MinAmount, all.MaxAmount = "", sql.NullInt64{}, sql.NullInt64{}
+		if journals, err = bk.dbJn.Fetch(all); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(journals, func(i, j int) bool { return journals[i].ID < journals[j].ID })
+	sort.SliceStable(journals, func(i, j int) bool { return journals[i].Date.Time.Before(journals[j].Date.Time) })
+
+	// amounts returns debit and credit of the journal in the currency of the ledgers.
+	amounts := func(j Journal) (int, int) {
+		if jnFetchOpts.Currency == "" {
+			return j.FuncLeft, j.FuncRight
+		}
+		return j.Left, j.Right
+	}
+	normal := func(j Journal) int {
+		l, r := amounts(j)
+		return normalBalance(j.Account, l-r)
+	}
+
+	opening := make(map[int]int)
+	openingAccounts := make(map[int]Account)
+	byCode := make(map[int][]Journal)
 	for _, j := range journals {
-		if _, ok := res[j.Code]; !ok {
-			res[j.Code] = []Journal{}
+		if !start.IsZero() && j.Date.Time.Before(start) {
+			opening[j.Code] += normal(j)
+			openingAccounts[j.Code] = j.Account
+			continue
+		}
+		byCode[j.Code] = append(byCode[j.Code], j)
+	}
+
+	accounts := make(map[int]Account)
+	if includeEmpty {
+		accs, err := bk.dbAc.Fetch(DBAccountsFetchOption{Lang: bk.lang})
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accs {
+			if len(jnFetchOpts.Code) == 0 || containsInt(jnFetchOpts.Code, a.Code) {
+				accounts[a.Code] = a
+			}
+		}
+	}
+	// accounts without journals in the period are in the ledger with their opening balance
+	for code, balance := range opening {
+		if balance != 0 && shown == nil {
+			accounts[code] = openingAccounts[code]
+		}
+	}
+	for code, items := range byCode {
+		for _, j := range items {
+			if shown == nil || shown[j.ID] {
+				accounts[code] = j.Account
+				break
+			}
 		}
-		res[j.Code] = append(res[j.Code], j)
 	}
-	return res, err
+
+	res := make([]Ledger, 0, len(accounts))
+	for code, a := range accounts {
+		items := byCode[code]
+		ledger := Ledger{
+			Account:  a,
+			Currency: reportCurrency(jnFetchOpts.Currency),
+			Opening:  opening[code],
+			Lines:    make([]LedgerLine, 0, len(items)),
+		}
+
+		balance := ledger.Opening
+		for _, j := range items {
+			balance += normal(j)
+			if shown != nil && !shown[j.ID] {
+				continue
+			}
+			l, r := amounts(j)
+			ledger.Lines = append(ledger.Lines, LedgerLine{Journal: j, Debit: l, Credit: r, Balance: balance})
+		}
+		ledger.Closing = balance
+
+		res = append(res, ledger)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Account.Code < res[j].Account.Code })
+
+	return res, nil
 }
 
+func containsInt(s []int, v int) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// PL is a profit and loss statement in the functional currency.
 type PL struct {
-	NetSales                            int
-	CostSales                           int
-	GrossProfit                         int
-	OperatingExpences                   int
-	OperatingIncome                     int
-	NonOperatingIncomes                 int
-	NonOperatingExpences                int
-	ExtraordinaryIncomes                int
-	ExtraordinaryExpences               int
-	IncomeBeforeProvisionForIncomeTaxes int
-	ProvisionForIncomeTaxes             int
-	NetIncome                           int
+	Currency string `json:"currency"`
+
+	NetSales                            int `json:"net_sales"`
+	CostSales                           int `json:"cost_sales"`
+	GrossProfit                         int `json:"gross_profit"`
+	OperatingExpences                   int `json:"operating_expences"`
+	OperatingIncome                     int `json:"operating_income"`
+	NonOperatingIncomes                 int `json:"non_operating_incomes"`
+	NonOperatingExpences                int `json:"non_operating_expences"`
+	ExtraordinaryIncomes                int `json:"extraordinary_incomes"`
+	ExtraordinaryExpences               int `json:"extraordinary_expences"`
+	IncomeBeforeProvisionForIncomeTaxes int `json:"income_before_provision_for_income_taxes"`
+	ProvisionForIncomeTaxes             int `json:"provision_for_income_taxes"`
+	NetIncome                           int `json:"net_income"`
 }
 
 type FetchPLOpts struct {
+	// Start is the first date of the statement.
+	// Zero Start is the first date after the last closed fiscal year, or the beginning of the book.
+	// If End is in a closed fiscal year, zero Start is the first date of the fiscal year.
 	Start time.Time
 	End   time.Time
 }
 
-func (bk *Bookkeeping) FetchPL(opt FetchPLOpts) (PL, error) {
-	pl := PL{}
-	dbOpt := DBJournalsFetchOption{}
-	if !opt.Start.IsZero() {
-		dbOpt.After = sql.NullTime{Time: opt.Start, Valid: true}
+// plFetchOption returns the option to fetch journals of the profit and loss statement, excluding closing entries.
+func (bk *Bookkeeping) plFetchOption(opt FetchPLOpts) (DBJournalsFetchOption, error) {
+	dbOpt := DBJournalsFetchOption{ExcludeClosing: true, Lang: bk.lang}
+
+	start := opt.Start
+	if start.IsZero() {
+		end := opt.End
+		if end.IsZero() {
+			end = time.Now()
+		}
+
+		var err error
+		start, err = bk.openingDate(end)
+		if err != nil {
+			return dbOpt, err
+		}
+		// the fiscal year ending on the end date is closed, and its closing entry is excluded
+		if start.After(end) {
+			if start, err = bk.fiscalYearStart(end); err != nil {
+				return dbOpt, err
+			}
+		}
+	}
+
+	if !start.IsZero() {
+		dbOpt.After = sql.NullTime{Time: start, Valid: true}
 	}
 	if !opt.End.IsZero() {
 		dbOpt.Before = sql.NullTime{Time: opt.End, Valid: true}
 	}
+	return dbOpt, nil
+}
+
+func (bk *Bookkeeping) FetchPL(opt FetchPLOpts) (PL, error) {
+	pl := PL{Currency: DefaultCurrency}
+	dbOpt, err := bk.plFetchOption(opt)
+	if err != nil {
+		return pl, err
+	}
 	sales, err := bk.dbJn.Fetch(dbOpt.CodeRange(4000, 4999))
 	if err != nil {
 		return pl, err
@@ -193,30 +729,67 @@ func (bk *Bookkeeping) FetchPL(opt FetchPLOpts) (PL, error) {
 	return pl, nil
 }
 
-type BS struct {
-	Date time.Time
+// PLDetail is a profit and loss statement with account lines under the sections of sales, costs, expences and so on.
+// Manufacturing costs (6xxx) are included in CostSales of PL, but have their own section.
+type PLDetail struct {
+	PL
 
-	TotalCurrentAssets    int
-	TotalNoncurrentAssets int
-	TotalAssets           int
+	Sections []Section `json:"sections"`
+}
 
-	TotalCurrentLiabilities    int
-	TotalNoncurrentLiabilities int
-	TotalLiabilities           int
+// FetchPLDetail returns the profit and loss statement with the balance of each account.
+func (bk *Bookkeeping) FetchPLDetail(opt FetchPLOpts) (PLDetail, error) {
+	pl, err := bk.FetchPL(opt)
+	if err != nil {
+		return PLDetail{}, err
+	}
+
+	dbOpt, err := bk.plFetchOption(opt)
+	if err != nil {
+		return PLDetail{}, err
+	}
+	jn, err := bk.dbJn.Fetch(dbOpt.CodeRange(4000, 9999))
+	if err != nil {
+		return PLDetail{}, err
+	}
 
-	OwnersCapital             int
-	RetainedErnings           int
-	TotalEquity               int
-	TotalLiabilitiesAndEquity int
+	return PLDetail{
+		PL:       pl,
+		Sections: buildSections(plSectionDefs, accountBalances(jn), nil, bk.lang),
+	}, nil
+}
+
+// BS is a balance sheet in the functional currency.
+type BS struct {
+	Date     time.Time `json:"date"`
+	Currency string    `json:"currency"`
+
+	TotalCurrentAssets    int `json:"total_current_assets"`
+	TotalNoncurrentAssets int `json:"total_noncurrent_assets"`
+	TotalAssets           int `json:"total_assets"`
+
+	TotalCurrentLiabilities    int `json:"total_current_liabilities"`
+	TotalNoncurrentLiabilities int `json:"total_noncurrent_liabilities"`
+	TotalLiabilities           int `json:"total_liabilities"`
+
+	OwnersCapital             int `json:"owners_capital"`
+	CapitalSurplus            int `json:"capital_surplus"`
+	RetainedErnings           int `json:"retained_earnings"`
+	TotalEquity               int `json:"total_equity"`
+	TotalLiabilitiesAndEquity int `json:"total_liabilities_and_equity"`
 }
 
 type FetchBSOpts struct {
 	Date time.Time
 }
 
+// FetchBS returns the balance sheet at the date.
+// Equity is summed up by the same code ranges as the sections of the detailed balance sheet.
+// Retained earnings are the balances of 33xx accounts, which closing entries carry net income into,
+// plus the net income after the last closed fiscal year.
 func (bk *Bookkeeping) FetchBS(opt FetchBSOpts) (BS, error) {
 
-	bs := BS{}
+	bs := BS{Currency: DefaultCurrency}
 
 	dbOpt := DBJournalsFetchOption{}
 	if !opt.Date.IsZero() {
@@ -265,58 +838,142 @@ func (bk *Bookkeeping) FetchBS(opt FetchBSOpts) (BS, error) {
 
 	bs.OwnersCapital = SumJournal(ownersCapital)
 
-	retainedEarnings, err := bk.dbJn.Fetch(dbOpt.CodeRange(3200, 3299))
+	capitalSurplus, err := bk.dbJn.Fetch(dbOpt.CodeRange(3200, 3299))
 	if err != nil {
 		return bs, err
 	}
 
-	plOpt := FetchPLOpts{}
-	if !opt.Date.IsZero() {
-		plOpt.End = opt.Date
+	bs.CapitalSurplus = SumJournal(capitalSurplus)
+
+	retainedEarnings, err := bk.dbJn.Fetch(dbOpt.CodeRange(3300, 3399))
+	if err != nil {
+		return bs, err
 	}
-	pl, err := bk.FetchPL(plOpt)
+
+	pl, err := bk.unclosedPL(opt.Date)
 	if err != nil {
 		return bs, err
 	}
 
 	bs.RetainedErnings = SumJournal(retainedEarnings) + pl.NetIncome
 
-	bs.TotalEquity = bs.OwnersCapital + bs.RetainedErnings
+	bs.TotalEquity = bs.OwnersCapital + bs.CapitalSurplus + bs.RetainedErnings
 
 	bs.TotalLiabilitiesAndEquity = bs.TotalLiabilities + bs.TotalEquity
 	return bs, nil
 }
 
+// BSDetail is a balance sheet with account lines under the sections of assets, liabilities and equity.
+type BSDetail struct {
+	BS
+
+	Sections []Section `json:"sections"`
+}
+
+// FetchBSDetail returns the balance sheet with the balance of each account.
+// Net income is shown as a line in the retained earnings section.
+func (bk *Bookkeeping) FetchBSDetail(opt FetchBSOpts) (BSDetail, error) {
+	bs, err := bk.FetchBS(opt)
+	if err != nil {
+		return BSDetail{}, err
+	}
+
+	dbOpt := DBJournalsFetchOption{Lang: bk.lang}
+	if !opt.Date.IsZero() {
+		dbOpt.Before = sql.NullTime{Time: opt.Date, Valid: true}
+	}
+	jn, err := bk.dbJn.Fetch(dbOpt.CodeRange(1000, 3999))
+	if err != nil {
+		return BSDetail{}, err
+	}
+
+	pl, err := bk.unclosedPL(opt.Date)
+	if err != nil {
+		return BSDetail{}, err
+	}
+
+	netIncome := AccountBalance{Account: Account{Name: localName(bk.lang, netIncomeName), IsBS: true}, Balance: pl.NetIncome}
+	extra := map[string][]AccountBalance{"33": {netIncome}}
+
+	return BSDetail{
+		BS:       bs,
+		Sections: buildSections(bsSectionDefs, accountBalances(jn), extra, bk.lang),
+	}, nil
+}
+
 func SumJournal(jnn ...[]Journal) int {
 	sum := 0
 
 	for _, jn := range jnn {
 		for _, j := range jn {
 			if j.Account.IsLeft {
-				sum += j.Left - j.Right
+				sum += j.FuncLeft - j.FuncRight
 			} else {
-				sum += j.Right - j.Left
+				sum += j.FuncRight - j.FuncLeft
 			}
 		}
 	}
 	return sum
 }
 
+// balance checks that debit and credit balance.
+// Journals in a single currency must balance in the currency.
+// Journals in multiple currencies must balance in the functional currency only,
+// because a cross-currency entry such as buying USD with JPY never balances in each currency.
+// Each currency balancing in itself also balances in the functional currency after convertEntry.
 func balance(jn []Journal) error {
-	leftSum, rightSum := 0, 0
+	leftSum, rightSum := make(map[string]int), make(map[string]int)
+	currencies := []string{}
+	funcLeft, funcRight := 0, 0
 
 	for _, item := range jn {
-		leftSum += item.Left
-		rightSum += item.Right
+		c := journalCurrency(item)
+		if _, ok := leftSum[c]; !ok {
+			currencies = append(currencies, c)
+		}
+		leftSum[c] += item.Left
+		rightSum[c] += item.Right
+		funcLeft += item.FuncLeft
+		funcRight += item.FuncRight
 	}
 
-	if leftSum == 0 || rightSum == 0 {
+	if len(currencies) == 0 {
 		return fmt.Errorf("credit or debit is zero-amount")
 	}
 
-	if leftSum != rightSum {
-		return fmt.Errorf("credit and debit are not balancing, debit: %v, credit: %v", leftSum, rightSum)
+	if len(currencies) > 1 {
+		if funcLeft == 0 || funcRight == 0 {
+			return fmt.Errorf("credit or debit is zero-amount in functional currency %s", DefaultCurrency)
+		}
+		if funcLeft != funcRight {
+			return fmt.Errorf("credit and debit are not balancing in functional currency %s, debit: %v, credit: %v",
+				DefaultCurrency, FormatAmount(funcLeft, DefaultCurrency), FormatAmount(funcRight, DefaultCurrency))
+		}
+		return nil
+	}
+
+	c := currencies[0]
+	if leftSum[c] == 0 || rightSum[c] == 0 {
+		return fmt.Errorf("credit or debit is zero-amount in %s", c)
+	}
+	if leftSum[c] != rightSum[c] {
+		return fmt.Errorf("credit and debit are not balancing in %s, debit: %v, credit: %v",
+			c, FormatAmount(leftSum[c], c), FormatAmount(rightSum[c], c))
 	}
 
 	return nil
 }
+
+func reportCurrency(c string) string {
+	if c == "" {
+		return DefaultCurrency
+	}
+	return c
+}
+
+func journalCurrency(j Journal) string {
+	if j.Currency == "" {
+		return DefaultCurrency
+	}
+	return j.Currency
+}
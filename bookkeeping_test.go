@@ -1,7 +1,11 @@
 package bookkeeping_test
 
 import (
+	"database/sql"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/yoskeoka/bookkeeping"
 )
@@ -127,12 +131,154 @@ func Test_FetchGL(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if bookkeeping.SumJournal(gl[1110]) != 1460000 {
-		t.Errorf("code 1110 balance must be 1460000, but got %v", bookkeeping.SumJournal(gl[1110]))
+	if ledgerOf(gl, 1110).Closing != 1460000 {
+		t.Errorf("code 1110 balance must be 1460000, but got %v", ledgerOf(gl, 1110).Closing)
 	}
 
-	if bookkeeping.SumJournal(gl[3100]) != 500000 {
-		t.Errorf("code 3100 balance must be 500000, but got %v", bookkeeping.SumJournal(gl[3100]))
+	if ledgerOf(gl, 3100).Closing != 500000 {
+		t.Errorf("code 3100 balance must be 500000, but got %v", ledgerOf(gl, 3100).Closing)
+	}
+
+	for i := 1; i < len(gl); i++ {
+		if gl[i-1].Account.Code >= gl[i].Account.Code {
+			t.Errorf("ledgers must be ordered by account code, but got %v before %v", gl[i-1].Account.Code, gl[i].Account.Code)
+		}
+	}
+}
+
+func Test_FetchGL_IncludeEmpty(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1110, 1210, 8300}, IncludeEmpty: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes := []int{}
+	for _, l := range gl {
+		codes = append(codes, l.Account.Code)
+	}
+	if !reflect.DeepEqual(codes, []int{1110, 1210, 8300}) {
+		t.Errorf("ledgers must be of 1110, 1210 and 8300, but got %v", codes)
+	}
+	if len(ledgerOf(gl, 1210).Lines) != 0 || ledgerOf(gl, 1210).Account.Name != "有形固定資産" {
+		t.Errorf("code 1210 must be an empty ledger, but got %+v", ledgerOf(gl, 1210))
+	}
+}
+
+func ledgerOf(gl []bookkeeping.Ledger, code int) bookkeeping.Ledger {
+	for _, l := range gl {
+		if l.Account.Code == code {
+			return l
+		}
+	}
+	return bookkeeping.Ledger{}
+}
+
+func Test_FetchGL_RunningBalance(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{
+		AccountIDList: []int{1110, 2101},
+		Start:         date(2020, 5, 10).Time,
+		End:           date(2020, 5, 16).Time,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cash := ledgerOf(gl, 1110)
+	if cash.Opening != 1050000 {
+		t.Errorf("code 1110 opening balance must be 1050000, but got %v", cash.Opening)
+	}
+	wantBalances := []int{2050000, 50000, 3050000}
+	if len(cash.Lines) != len(wantBalances) {
+		t.Fatalf("code 1110 must have %v lines, but got %v", len(wantBalances), len(cash.Lines))
+	}
+	for i, want := range wantBalances {
+		if cash.Lines[i].Balance != want {
+			t.Errorf("code 1110 line %d balance must be %v, but got %v", i, want, cash.Lines[i].Balance)
+		}
+	}
+	if cash.Closing != 3050000 {
+		t.Errorf("code 1110 closing balance must be 3050000, but got %v", cash.Closing)
+	}
+
+	// credit side account
+	loan := ledgerOf(gl, 2101)
+	if loan.Opening != 0 || loan.Closing != 1000000 {
+		t.Errorf("code 2101 must be opening 0 and closing 1000000, but got %v and %v", loan.Opening, loan.Closing)
+	}
+}
+
+func Test_FetchGL_Filter(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{
+		Start:      date(2020, 5, 10).Time,
+		End:        date(2020, 5, 16).Time,
+		DescFilter: "運転資金",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gl) != 2 {
+		t.Fatalf("FetchGL() must return ledgers of 1110 and 2101 with the matching lines, but got %+v", gl)
+	}
+
+	// balances are of all of the journals of the account
+	cash := ledgerOf(gl, 1110)
+	if cash.Opening != 1050000 || cash.Closing != 3050000 {
+		t.Errorf("code 1110 must be opening 1050000 and closing 3050000, but got %v and %v", cash.Opening, cash.Closing)
+	}
+	if len(cash.Lines) != 1 || cash.Lines[0].Description != "運転資金" || cash.Lines[0].Balance != 2050000 {
+		t.Errorf("code 1110 must have the matching line of balance 2050000, but got %+v", cash.Lines)
+	}
+}
+
+func Test_FetchGL_OpeningOnly(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	all, err := bk.FetchGL()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no journals in the period, so every ledger has only its opening balance
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{Start: date(2030, 3, 1).Time, End: date(2030, 3, 31).Time})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := 0
+	for _, l := range all {
+		if l.Closing != 0 {
+			want++
+		}
+	}
+	if len(gl) != want {
+		t.Fatalf("FetchGL() must return %v ledgers with opening balances, but got %v", want, len(gl))
+	}
+
+	cash := ledgerOf(gl, 1110)
+	if wantCash := ledgerOf(all, 1110).Closing; cash.Opening != wantCash || cash.Closing != wantCash || len(cash.Lines) != 0 {
+		t.Errorf("code 1110 must be opening and closing %v without lines, but got %v, %v and %v lines",
+			wantCash, cash.Opening, cash.Closing, len(cash.Lines))
+	}
+	if cash.Account.Name == "" {
+		t.Errorf("code 1110 ledger must have the account")
 	}
 }
 
@@ -187,3 +333,685 @@ func Test_FetchBS(t *testing.T) {
 		t.Errorf("bs.TotalLiabilitiesAndEquity must be 2960000, but got %v", bs.TotalLiabilitiesAndEquity)
 	}
 }
+
+func Test_FetchEntry(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	id, err := bk.PostEntry(bookkeeping.Entry{
+		Memo: "給与支払",
+		Journals: []bookkeeping.Journal{
+			{Date: date(2020, 5, 20), Code: 7200, Left: 300000, Description: "事務員A給与"},
+			{Date: date(2020, 5, 20), Code: 1110, Right: 290000, Description: "給与"},
+			{Date: date(2020, 5, 20), Code: 2103, Right: 10000, Description: "源泉所得税"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := bk.FetchEntry(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Memo != "給与支払" {
+		t.Errorf("entry memo must be '給与支払', but got '%v'", e.Memo)
+	}
+	if !e.Date.Time.Equal(date(2020, 5, 20).Time) {
+		t.Errorf("entry date must be 2020/05/20, but got %v", e.Date.Time)
+	}
+	if len(e.Journals) != 3 {
+		t.Fatalf("entry must have 3 journals, but got %v", len(e.Journals))
+	}
+	for _, j := range e.Journals {
+		if j.EntryID != id {
+			t.Errorf("journal %d must reference entry %d, but got %d", j.ID, id, j.EntryID)
+		}
+	}
+
+	if _, err := bk.FetchEntry(id + 1); err == nil {
+		t.Errorf("FetchEntry() with unknown ID must return error")
+	}
+}
+
+func Test_PostEntry_KeepJournals(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	jn := []bookkeeping.Journal{{Code: 1110, Left: 500000}, {Code: 3100, Right: 500000}}
+	if _, err := bk.PostEntry(bookkeeping.Entry{Date: date(2020, 5, 1), Journals: jn}); err != nil {
+		t.Fatal(err)
+	}
+	if jn[0].Date.Valid || jn[0].Currency != "" {
+		t.Errorf("PostEntry() must not modify journals of the entry, but got %+v", jn[0])
+	}
+
+	entries := []bookkeeping.Entry{{Date: date(2020, 5, 2), Journals: jn}}
+	if _, err := bk.PostEntries(entries); err != nil {
+		t.Fatal(err)
+	}
+	if jn[0].Date.Valid || jn[0].Currency != "" {
+		t.Errorf("PostEntries() must not modify journals of the entries, but got %+v", jn[0])
+	}
+}
+
+func Test_PostEntry_DifferentDates(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	_, err := bk.PostEntry(bookkeeping.Entry{
+		Journals: []bookkeeping.Journal{
+			{Date: date(2020, 5, 1), Code: 1110, Left: 500000},
+			{Date: date(2020, 5, 2), Code: 3100, Right: 500000},
+		},
+	})
+	if err == nil {
+		t.Errorf("PostEntry() with journals of different dates must return error")
+	}
+}
+
+func Test_CheckEntry(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	e := bookkeeping.Entry{
+		Date:     date(2020, 5, 1),
+		Journals: []bookkeeping.Journal{{Code: 1110, Left: 500000}},
+	}
+	if err := bk.CheckEntry(e); err == nil {
+		t.Errorf("CheckEntry() with journals not balancing must return error")
+	}
+
+	e.Journals = append(e.Journals, bookkeeping.Journal{Code: 3100, Right: 500000})
+	if err := bk.CheckEntry(e); err != nil {
+		t.Errorf("CheckEntry() error = %v", err)
+	}
+	if e.Journals[0].Date.Valid {
+		t.Errorf("CheckEntry() must not modify journals of the entry")
+	}
+
+	gl, err := bk.FetchGL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gl) != 0 {
+		t.Errorf("CheckEntry() must not post the entry")
+	}
+}
+
+func Test_Reverse(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	revID, err := bk.Reverse(3, date(2020, 6, 1).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rev, err := bk.FetchEntry(revID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.ReversalOf != 3 {
+		t.Errorf("reversal entry must link to entry 3, but got %v", rev.ReversalOf)
+	}
+
+	orig, err := bk.FetchEntry(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orig.ReversedBy != revID {
+		t.Errorf("entry 3 must be reversed by entry %v, but got %v", revID, orig.ReversedBy)
+	}
+
+	for i, j := range rev.Journals {
+		if j.Left != orig.Journals[i].Right || j.Right != orig.Journals[i].Left {
+			t.Errorf("reversal journal %d must swap debit and credit of %+v, but got %+v", i, orig.Journals[i], j)
+		}
+	}
+
+	pl, err := bk.FetchPL(bookkeeping.FetchPLOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.NetIncome != 1050000 {
+		t.Errorf("pl.NetIncome must be 1050000 after reversal, but got %v", pl.NetIncome)
+	}
+
+	if _, err := bk.Reverse(3, date(2020, 6, 1).Time); err == nil {
+		t.Errorf("Reverse() of reversed entry must return error")
+	}
+	if _, err := bk.Reverse(revID, date(2020, 6, 1).Time); err == nil {
+		t.Errorf("Reverse() of reversal entry must return error")
+	}
+}
+
+func Test_AddAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		account bookkeeping.Account
+		wantErr bool
+	}{
+		{"ok, current asset", bookkeeping.Account{Code: 1140, Name: "前払費用", IsBS: true, IsLeft: true}, false},
+		{"ok, non operating income", bookkeeping.Account{Code: 8110, Name: "受取利息", IsBS: false, IsLeft: false}, false},
+		{"error, asset on credit side", bookkeeping.Account{Code: 1140, Name: "前払費用", IsBS: true, IsLeft: false}, true},
+		{"error, expense as BS", bookkeeping.Account{Code: 7400, Name: "広告宣伝費", IsBS: true, IsLeft: true}, true},
+		{"error, not 4 digits", bookkeeping.Account{Code: 114, Name: "前払費用", IsBS: true, IsLeft: true}, true},
+		{"error, out of code range", bookkeeping.Account{Code: 8500, Name: "不明", IsBS: false, IsLeft: true}, true},
+		{"error, duplicated code", bookkeeping.Account{Code: 1110, Name: "現金", IsBS: true, IsLeft: true}, true},
+		{"error, empty name", bookkeeping.Account{Code: 1140, IsBS: true, IsLeft: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tdb := NewTestDB(t)
+			initAccounts(t, tdb)
+
+			bk := bookkeeping.NewBookkeeping(tdb)
+			err := bk.AddAccount(tt.account)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_DeactivateAccount(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	if err := bk.RenameAccount(1130, "棚卸商品"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bk.DeactivateAccount(1130); err != nil {
+		t.Fatal(err)
+	}
+
+	err := bk.Post([]bookkeeping.Journal{
+		{Date: date(2020, 6, 1), Code: 1130, Left: 100000},
+		{Date: date(2020, 6, 1), Code: 1110, Right: 100000},
+	})
+	if err == nil {
+		t.Errorf("Post() to deactivated account must return error")
+	}
+
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1130}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gl) != 1 || len(gl[0].Lines) != 1 {
+		t.Fatalf("deactivated account must remain in general ledger, but got %v", gl)
+	}
+	if gl[0].Account.Name != "棚卸商品" || !gl[0].Account.Inactive {
+		t.Errorf("account must be renamed and inactive, but got %+v", gl[0].Account)
+	}
+}
+
+func Test_FetchBSDetail(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	bs, err := bk.FetchBSDetail(bookkeeping.FetchBSOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bs.Sections) != 3 {
+		t.Fatalf("bs.Sections must be assets, liabilities and equity, but got %v sections", len(bs.Sections))
+	}
+
+	assets := bs.Sections[0]
+	if assets.Total != bs.TotalAssets {
+		t.Errorf("assets section total must be %v, but got %v", bs.TotalAssets, assets.Total)
+	}
+	if len(assets.Sections) != 2 || assets.Sections[0].Prefix != "11" || assets.Sections[0].Total != bs.TotalCurrentAssets {
+		t.Errorf("assets section must have current assets of %v first, but got %+v", bs.TotalCurrentAssets, assets.Sections)
+	}
+	cash := assets.Sections[0].Sections[0]
+	if cash.Prefix != "111" || len(cash.Lines) != 1 || cash.Lines[0].Account.Code != 1110 || cash.Lines[0].Balance != 1460000 {
+		t.Errorf("cash section must have 1110 line of 1460000, but got %+v", cash)
+	}
+
+	liabilities := bs.Sections[1]
+	if liabilities.Total != bs.TotalLiabilities {
+		t.Errorf("liabilities section total must be %v, but got %v", bs.TotalLiabilities, liabilities.Total)
+	}
+
+	equity := bs.Sections[2]
+	if equity.Total != bs.TotalEquity {
+		t.Errorf("equity section total must be %v, but got %v", bs.TotalEquity, equity.Total)
+	}
+}
+
+func Test_FetchBSDetail_Equity(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	if err := bk.Post([]bookkeeping.Journal{
+		{Date: date(2021, 3, 31), Code: 1110, Left: 300000},
+		{Date: date(2021, 3, 31), Code: 3200, Right: 200000},
+		{Date: date(2021, 3, 31), Code: 3300, Right: 100000},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := bk.FetchBSDetail(bookkeeping.FetchBSOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs.CapitalSurplus != 200000 {
+		t.Errorf("bs.CapitalSurplus must be 200000, but got %v", bs.CapitalSurplus)
+	}
+
+	totals := map[string]int{}
+	for _, sec := range bs.Sections[2].Sections {
+		totals[sec.Prefix] = sec.Total
+	}
+	if totals["31"] != bs.OwnersCapital || totals["32"] != bs.CapitalSurplus || totals["33"] != bs.RetainedErnings {
+		t.Errorf("equity sections must be %v, %v and %v, but got %v", bs.OwnersCapital, bs.CapitalSurplus, bs.RetainedErnings, totals)
+	}
+	if bs.TotalLiabilitiesAndEquity != bs.TotalAssets {
+		t.Errorf("bs.TotalLiabilitiesAndEquity must be %v, but got %v", bs.TotalAssets, bs.TotalLiabilitiesAndEquity)
+	}
+}
+
+func Test_FetchPLDetail(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	pl, err := bk.FetchPLDetail(bookkeeping.FetchPLOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totals := map[string]int{}
+	for _, sec := range pl.Sections {
+		totals[sec.Prefix] = sec.Total
+	}
+
+	if totals["4"] != pl.NetSales {
+		t.Errorf("net sales section total must be %v, but got %v", pl.NetSales, totals["4"])
+	}
+	if totals["5"]+totals["6"] != pl.CostSales {
+		t.Errorf("cost sales sections total must be %v, but got %v", pl.CostSales, totals["5"]+totals["6"])
+	}
+	if totals["7"] != pl.OperatingExpences {
+		t.Errorf("operating expences section total must be %v, but got %v", pl.OperatingExpences, totals["7"])
+	}
+
+	var opex bookkeeping.Section
+	for _, sec := range pl.Sections {
+		if sec.Prefix == "7" {
+			opex = sec
+		}
+	}
+	want := map[int]int{7200: 300000, 7300: 350000}
+	for _, child := range opex.Sections {
+		for _, l := range child.Lines {
+			if want[l.Account.Code] != l.Balance {
+				t.Errorf("account %d balance must be %v, but got %v", l.Account.Code, want[l.Account.Code], l.Balance)
+			}
+			delete(want, l.Account.Code)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("operating expences section must have lines of %v", want)
+	}
+}
+
+func Test_PostEntries(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	_, err := bk.PostEntries([]bookkeeping.Entry{
+		{Journals: []bookkeeping.Journal{
+			{Date: date(2020, 5, 1), Code: 1110, Left: 500000},
+			{Date: date(2020, 5, 1), Code: 3100, Right: 500000},
+		}},
+		{Journals: []bookkeeping.Journal{
+			{Date: date(2020, 5, 2), Code: 7300, Left: 1000},
+			{Date: date(2020, 5, 2), Code: 1110, Right: 900},
+		}},
+	})
+
+	var entryErr *bookkeeping.EntryError
+	if !errors.As(err, &entryErr) || entryErr.Index != 1 {
+		t.Fatalf("PostEntries() must return EntryError of index 1, but got %v", err)
+	}
+
+	gl, err := bk.FetchGL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gl) != 0 {
+		t.Errorf("PostEntries() must not post any entry on error, but got %v", gl)
+	}
+
+	ids, err := bk.PostEntries([]bookkeeping.Entry{
+		{Journals: []bookkeeping.Journal{
+			{Date: date(2020, 5, 1), Code: 1110, Left: 500000},
+			{Date: date(2020, 5, 1), Code: 3100, Right: 500000},
+		}},
+		{Journals: []bookkeeping.Journal{
+			{Date: date(2020, 5, 2), Code: 7300, Left: 1000},
+			{Date: date(2020, 5, 2), Code: 1110, Right: 1000},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("PostEntries() must return 2 IDs, but got %v", ids)
+	}
+}
+
+func setUSDRate(t *testing.T, bk *bookkeeping.Bookkeeping, d sql.NullTime, rate string) {
+	t.Helper()
+	if err := bk.SetRates(bookkeeping.ExchangeRate{Base: "USD", Quote: "JPY", Date: d, Rate: rate}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Post_Currency(t *testing.T) {
+	tests := []struct {
+		name    string
+		noRate  bool
+		jn      []bookkeeping.Journal
+		wantErr bool
+	}{
+		{"ok, USD", false, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10025},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 10025},
+		}, false},
+		{"ok, balancing in each currency", false, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10025},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 10025},
+			{Date: date(2020, 6, 1), Code: 1110, Left: 10000},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "JPY", Right: 10000},
+		}, false},
+		{"ok, balancing in functional currency", false, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10000},
+			{Date: date(2020, 6, 1), Code: 3100, Right: 10800},
+		}, false},
+		{"error, not balancing in functional currency", false, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10000},
+			{Date: date(2020, 6, 1), Code: 3100, Right: 10000},
+		}, true},
+		{"ok, rounding differences in each currency", false, []bookkeeping.Journal{
+			// USD 0.15 is JPY 16, but USD 0.05 is JPY 5
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 15},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 5},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 5},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 5},
+			{Date: date(2020, 6, 1), Code: 1110, Left: 10000},
+			{Date: date(2020, 6, 1), Code: 3100, Right: 10000},
+		}, false},
+		{"ok, cross-currency not balancing in USD", false, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10000},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 5000},
+			{Date: date(2020, 6, 1), Code: 3100, Right: 5400},
+		}, false},
+		{"error, cross-currency not balancing in functional currency", false, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10000},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 5000},
+			{Date: date(2020, 6, 1), Code: 3100, Right: 5000},
+		}, true},
+		{"ok, balancing in neither currency but in functional currency", false, []bookkeeping.Journal{
+			// USD 100.00 + JPY 800 = JPY 11600 = USD 50.00 + JPY 6200
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10000},
+			{Date: date(2020, 6, 1), Code: 1110, Left: 800},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 5000},
+			{Date: date(2020, 6, 1), Code: 3100, Right: 6200},
+		}, false},
+		{"error, balancing in neither currency nor in functional currency", false, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10000},
+			{Date: date(2020, 6, 1), Code: 1110, Left: 800},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 5000},
+			{Date: date(2020, 6, 1), Code: 3100, Right: 6000},
+		}, true},
+		{"error, no exchange rate", true, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10025},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 10025},
+		}, true},
+		{"error, unsupported currency", false, []bookkeeping.Journal{
+			{Date: date(2020, 6, 1), Code: 1110, Currency: "XYZ", Left: 10000},
+			{Date: date(2020, 6, 1), Code: 3100, Currency: "XYZ", Right: 10000},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tdb := NewTestDB(t)
+			initAccounts(t, tdb)
+
+			bk := bookkeeping.NewBookkeeping(tdb)
+			if !tt.noRate {
+				setUSDRate(t, bk, date(2020, 5, 31), "108")
+			}
+			err := bk.Post(tt.jn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Post() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_FetchBS_FunctionalCurrency(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	setUSDRate(t, bk, date(2020, 5, 31), "108")
+	if err := bk.Post([]bookkeeping.Journal{
+		{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10025},
+		{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 10025},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := bk.FetchBS(bookkeeping.FetchBSOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// USD 100.25 is JPY 10827 at 108
+	if bs.Currency != "JPY" || bs.TotalAssets != 2970827 {
+		t.Errorf("bs.TotalAssets must be 2970827 JPY, but got %v %v", bs.TotalAssets, bs.Currency)
+	}
+
+	ledgers, err := bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1110}, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledgers) != 1 || ledgers[0].Currency != "USD" || ledgers[0].Closing != 10025 {
+		t.Errorf("USD ledger of 1110 must close at 10025, but got %+v", ledgers)
+	}
+
+	// amount filters are in the currency of the ledgers
+	ledgers, err = bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1110}, MinAmount: 10827, MaxAmount: 10827})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledgers) != 1 || len(ledgers[0].Lines) != 1 || ledgers[0].Lines[0].Debit != 10827 {
+		t.Errorf("JPY ledger of 1110 must have the USD journal of JPY 10827, but got %+v", ledgers)
+	}
+	ledgers, err = bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1110}, Currency: "USD", MinAmount: 10827})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledgers) != 0 {
+		t.Errorf("USD ledger of 1110 must have no journal of USD 108.27 or more, but got %+v", ledgers)
+	}
+}
+
+func Test_Post_InverseRate(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	if err := bk.SetRates(bookkeeping.ExchangeRate{Base: "JPY", Quote: "USD", Date: date(2020, 5, 31), Rate: "0.008"}); err != nil {
+		t.Fatal(err)
+	}
+	id, err := bk.PostEntry(bookkeeping.Entry{Journals: []bookkeeping.Journal{
+		{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 101},
+		{Date: date(2020, 6, 1), Code: 3100, Currency: "USD", Right: 101},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := bk.FetchEntry(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// USD 1.01 is JPY 126.25 at 125, rounded to 126
+	if e.Journals[0].FuncLeft != 126 || e.Journals[1].FuncRight != 126 {
+		t.Errorf("functional amounts must be 126, but got %+v", e.Journals)
+	}
+}
+
+func Test_SetRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    bookkeeping.ExchangeRate
+		wantErr bool
+	}{
+		{"ok", bookkeeping.ExchangeRate{Base: "USD", Quote: "JPY", Date: date(2020, 6, 1), Rate: "108.25"}, false},
+		{"error, same currency", bookkeeping.ExchangeRate{Base: "JPY", Quote: "JPY", Date: date(2020, 6, 1), Rate: "1"}, true},
+		{"error, unsupported currency", bookkeeping.ExchangeRate{Base: "XYZ", Quote: "JPY", Date: date(2020, 6, 1), Rate: "1"}, true},
+		{"error, no date", bookkeeping.ExchangeRate{Base: "USD", Quote: "JPY", Rate: "108"}, true},
+		{"error, negative rate", bookkeeping.ExchangeRate{Base: "USD", Quote: "JPY", Date: date(2020, 6, 1), Rate: "-108"}, true},
+		{"error, not a number", bookkeeping.ExchangeRate{Base: "USD", Quote: "JPY", Date: date(2020, 6, 1), Rate: "abc"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tdb := NewTestDB(t)
+
+			bk := bookkeeping.NewBookkeeping(tdb)
+			err := bk.SetRates(tt.rate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetRates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_SetRates_Normalize(t *testing.T) {
+	tdb := NewTestDB(t)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	rates := []bookkeeping.ExchangeRate{{Base: "USD", Quote: "JPY", Date: date(2020, 6, 1), Rate: "+108.25"}}
+	if err := bk.SetRates(rates...); err != nil {
+		t.Fatal(err)
+	}
+	if rates[0].Rate != "+108.25" {
+		t.Errorf("SetRates() must not modify the rates of the caller, but got %v", rates[0].Rate)
+	}
+
+	got, err := bk.FetchRates(bookkeeping.FetchRatesOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Rate != "108.25" {
+		t.Errorf("SetRates() must store the normalized rate 108.25, but got %+v", got)
+	}
+}
+
+func Test_SetRates_Day(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	// a rate set at the time of the day is used for the whole day
+	afternoon := date(2020, 6, 1).Time.Add(15 * time.Hour)
+	if err := bk.SetRates(bookkeeping.ExchangeRate{Base: "USD", Quote: "JPY", Date: sql.NullTime{Time: afternoon, Valid: true}, Rate: "108"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bk.Post([]bookkeeping.Journal{
+		{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10000},
+		{Date: date(2020, 6, 1), Code: 3100, Right: 10800},
+	}); err != nil {
+		t.Errorf("Post() on the date of the rate error = %v", err)
+	}
+
+	got, err := bk.FetchRates(bookkeeping.FetchRatesOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].Date.Time.Equal(date(2020, 6, 1).Time) {
+		t.Errorf("SetRates() must store the rate on 2020/06/01, but got %+v", got)
+	}
+}
+
+func Test_Revalue(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	setUSDRate(t, bk, date(2020, 6, 1), "108")
+	setUSDRate(t, bk, date(2020, 6, 30), "110")
+	if err := bk.Post([]bookkeeping.Journal{
+		{Date: date(2020, 6, 1), Code: 1110, Currency: "USD", Left: 10000},
+		{Date: date(2020, 6, 1), Code: 3100, Right: 10800},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := bk.Revalue(date(2020, 6, 30).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == 0 {
+		t.Fatal("Revalue() must post an entry")
+	}
+
+	pl, err := bk.FetchPL(bookkeeping.FetchPLOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.NonOperatingIncomes != 200 {
+		t.Errorf("pl.NonOperatingIncomes must be 200, but got %v", pl.NonOperatingIncomes)
+	}
+
+	bs, err := bk.FetchBS(bookkeeping.FetchBSOpts{Date: date(2020, 6, 30).Time})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs.TotalAssets != 11000 || bs.TotalLiabilitiesAndEquity != 11000 {
+		t.Errorf("bs must be balancing at 11000, but got %+v", bs)
+	}
+
+	id, err = bk.Revalue(date(2020, 6, 30).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 0 {
+		t.Errorf("Revalue() must post nothing after revaluation, but got entry %v", id)
+	}
+
+	setUSDRate(t, bk, date(2020, 7, 31), "105")
+	if _, err := bk.Revalue(date(2020, 7, 31).Time); err != nil {
+		t.Fatal(err)
+	}
+	pl, err = bk.FetchPL(bookkeeping.FetchPLOpts{Start: date(2020, 7, 1).Time})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.NonOperatingExpences != 500 {
+		t.Errorf("pl.NonOperatingExpences must be 500, but got %v", pl.NonOperatingExpences)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func accountAddCmd() command {
+	fset := flag.NewFlagSet("bk account add", flag.ExitOnError)
+	opts := &accountAddOpts{}
+
+	fset.IntVar(&opts.code, "code", 0, "Account code (4 digits)")
+	fset.StringVar(&opts.name, "name", "", "Account name")
+	fset.StringVar(&opts.bspl, "type", "", "Account type. (bs or pl)")
+	fset.StringVar(&opts.side, "side", "", "Normal side of the account. (debit or credit)")
+
+	return command{
+		name:        "add",
+		description: "Add account",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return accountAdd(opts, glOpts)
+		},
+	}
+}
+
+type accountAddOpts struct {
+	code int
+	name string
+	bspl string
+	side string
+}
+
+func accountAdd(opts *accountAddOpts, glOpts *globalOpts) error {
+	a := bookkeeping.Account{Code: opts.code, Name: opts.name}
+
+	switch opts.bspl {
+	case "bs":
+		a.IsBS = true
+	case "pl":
+		a.IsBS = false
+	default:
+		return fmt.Errorf("-type must be 'bs' or 'pl', but got '%s'", opts.bspl)
+	}
+
+	switch opts.side {
+	case "debit":
+		a.IsLeft = true
+	case "credit":
+		a.IsLeft = false
+	default:
+		return fmt.Errorf("-side must be 'debit' or 'credit', but got '%s'", opts.side)
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	return bk.AddAccount(a)
+}
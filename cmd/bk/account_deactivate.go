@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func accountDeactivateCmd() command {
+	fset := flag.NewFlagSet("bk account deactivate", flag.ExitOnError)
+	opts := &accountDeactivateOpts{}
+
+	fset.IntVar(&opts.code, "code", 0, "Account code")
+
+	return command{
+		name:        "deactivate",
+		description: "Deactivate account for new journals",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return accountDeactivate(opts, glOpts)
+		},
+	}
+}
+
+type accountDeactivateOpts struct {
+	code int
+}
+
+func accountDeactivate(opts *accountDeactivateOpts, glOpts *globalOpts) error {
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	return bk.DeactivateAccount(opts.code)
+}
@@ -4,7 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/yoskeoka/bookkeeping"
@@ -15,6 +15,9 @@ func accountCmd() command {
 
 	subcommands := []command{
 		accountListCmd(),
+		accountAddCmd(),
+		accountRenameCmd(),
+		accountDeactivateCmd(),
 	}
 
 	fset.Usage = func() {
@@ -65,11 +68,12 @@ type accountListOpts struct {
 
 func accountList(opts *accountListOpts, glOpts *globalOpts) error {
 
-	db, err := bookkeeping.NewDB(filepath.Join(glOpts.dataDir, databaseName))
+	db, err := openBook(glOpts)
 	if err != nil {
 		return err
 	}
 	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
 
 	fetchAcOpts := bookkeeping.FetchAcOpts{
 		CodeFilter: opts.codeFilter,
@@ -81,22 +85,47 @@ func accountList(opts *accountListOpts, glOpts *globalOpts) error {
 		return err
 	}
 
-	if len(items) > 0 {
-		printAccounts(glOpts.output, items)
-	} else {
+	if len(items) == 0 && (glOpts.format == "" || glOpts.format == "text") {
 		fmt.Fprintln(glOpts.output, "no accounts found")
+		return nil
 	}
-	return nil
+
+	return render(glOpts, report{
+		data:  items,
+		text:  func(w io.Writer) { printAccounts(w, glOpts.locale, items) },
+		table: func() [][]string { return accountsTable(items) },
+	})
 }
 
-func printAccounts(w io.Writer, items []bookkeeping.Account) {
-	fmt.Fprintln(w, "Accounts List")
-	fprintLFW(w, "code", 10)
-	fprintLFW(w, "name", 40)
-	fprintLFW(w, "bs/pl", 6)
-	fprintLFW(w, "debit/credit", 14)
+func accountsTable(items []bookkeeping.Account) [][]string {
+	rows := [][]string{{"code", "name", "bs/pl", "debit/credit", "status"}}
+	for _, item := range items {
+		bspl := "PL"
+		if item.IsBS {
+			bspl = "BS"
+		}
+		dc := "credit"
+		if item.IsLeft {
+			dc = "debit"
+		}
+		status := "active"
+		if item.Inactive {
+			status = "inactive"
+		}
+		rows = append(rows, []string{strconv.Itoa(item.Code), item.Name, bspl, dc, status})
+	}
+	return rows
+}
+
+func printAccounts(w io.Writer, lang string, items []bookkeeping.Account) {
+	fmt.Fprintln(w, label(lang, "Accounts List"))
+	fprintLFW(w, label(lang, "code"), 10)
+	fprintLFW(w, label(lang, "name"), 40)
+	fprintLFW(w, label(lang, "bs/pl"), 6)
+	fprintLFW(w, label(lang, "debit/credit"), 14)
+	fprintLFW(w, label(lang, "status"), 10)
 	fmt.Fprintln(w)
-	fmt.Fprintln(w, strings.Repeat("-", 70))
+	fmt.Fprintln(w, strings.Repeat("-", 80))
 
 	for _, item := range items {
 		fprintLFW(w, fmt.Sprintf("%d", item.Code), 10)
@@ -111,7 +140,12 @@ func printAccounts(w io.Writer, items []bookkeeping.Account) {
 			dc = "debit"
 		}
 
-		fprintLFW(w, dc, 14)
+		fprintLFW(w, label(lang, dc), 14)
+		status := "active"
+		if item.Inactive {
+			status = "inactive"
+		}
+		fprintLFW(w, label(lang, status), 10)
 		fmt.Fprintln(w)
 	}
 }
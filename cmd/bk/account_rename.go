@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func accountRenameCmd() command {
+	fset := flag.NewFlagSet("bk account rename", flag.ExitOnError)
+	opts := &accountRenameOpts{}
+
+	fset.IntVar(&opts.code, "code", 0, "Account code")
+	fset.StringVar(&opts.name, "name", "", "New account name")
+
+	return command{
+		name:        "rename",
+		description: "Rename account",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return accountRename(opts, glOpts)
+		},
+	}
+}
+
+type accountRenameOpts struct {
+	code int
+	name string
+}
+
+func accountRename(opts *accountRenameOpts, glOpts *globalOpts) error {
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
+
+	return bk.RenameAccount(opts.code, opts.name)
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func arCmd() command {
+	fset := flag.NewFlagSet("bk ar", flag.ExitOnError)
+
+	subcommands := []command{
+		arAddCustomerCmd(),
+		arCustomersCmd(),
+		arInvoiceCmd(),
+		arInvoicesCmd(),
+		arPayCmd(),
+		arAgingCmd(),
+	}
+
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Subcommands:")
+		for _, cmd := range subcommands {
+			if cmd.fset == nil || cmd.fn == nil {
+				continue // skip not implemented
+			}
+
+			fmt.Fprintf(fset.Output(), "  %s:%s%s\n", cmd.name, strings.Repeat(" ", 14-len(cmd.name)), cmd.description)
+		}
+	}
+
+	return command{
+		name:          "ar",
+		description:   "Manage customers, invoices and payments of accounts receivable",
+		hasSubcommand: true,
+		fset:          fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return subcmd("bk ar", subcommands, fset.Args(), glOpts)
+		},
+	}
+}
+
+func arAddCustomerCmd() command {
+	fset := flag.NewFlagSet("bk ar add-customer", flag.ExitOnError)
+	opts := &arAddCustomerOpts{}
+	fset.StringVar(&opts.name, "name", "", "Customer name.")
+
+	return command{
+		name:        "add-customer",
+		description: "Add a customer",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return arAddCustomer(opts, glOpts)
+		},
+	}
+}
+
+type arAddCustomerOpts struct {
+	name string
+}
+
+func arAddCustomer(opts *arAddCustomerOpts, glOpts *globalOpts) error {
+	if opts.name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	id, err := bk.AddCustomer(opts.name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "customer %d '%s' added\n", id, opts.name)
+	return nil
+}
+
+func arCustomersCmd() command {
+	fset := flag.NewFlagSet("bk ar customers", flag.ExitOnError)
+
+	return command{
+		name:        "customers",
+		description: "List customers",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return arCustomers(glOpts)
+		},
+	}
+}
+
+func arCustomers(glOpts *globalOpts) error {
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	cs, err := bk.FetchCustomers()
+	if err != nil {
+		return err
+	}
+
+	return render(glOpts, report{
+		data: cs,
+		text: func(w io.Writer) {
+			fprintLFW(w, "id", 6)
+			fmt.Fprintln(w, "name")
+			fmt.Fprintln(w, strings.Repeat("-", 40))
+			for _, c := range cs {
+				fprintLFW(w, c.ID, 6)
+				fmt.Fprintln(w, c.Name)
+			}
+		},
+		table: func() [][]string {
+			rows := [][]string{{"id", "name"}}
+			for _, c := range cs {
+				rows = append(rows, []string{strconv.Itoa(c.ID), c.Name})
+			}
+			return rows
+		},
+	})
+}
+
+// findCustomer returns the customer of the ID or the name.
+func findCustomer(cs []bookkeeping.Customer, s string) (bookkeeping.Customer, error) {
+	id, err := strconv.Atoi(s)
+	for _, c := range cs {
+		if (err == nil && c.ID == id) || c.Name == s {
+			return c, nil
+		}
+	}
+	return bookkeeping.Customer{}, fmt.Errorf("customer '%s' is not found", s)
+}
+
+func fetchCustomer(bk *bookkeeping.Bookkeeping, s string) (bookkeeping.Customer, error) {
+	cs, err := bk.FetchCustomers()
+	if err != nil {
+		return bookkeeping.Customer{}, err
+	}
+	return findCustomer(cs, s)
+}
+
+// parseARAmount parses an amount in DefaultCurrency, which is the currency of invoices.
+func parseARAmount(s string) (int, error) {
+	m, err := bookkeeping.ParseMoney(s)
+	if err != nil {
+		return 0, err
+	}
+	if m.Currency != bookkeeping.DefaultCurrency {
+		return 0, fmt.Errorf("amount must be in %s, but got '%s'", bookkeeping.DefaultCurrency, s)
+	}
+	return m.Amount, nil
+}
+
+func arInvoiceCmd() command {
+	fset := flag.NewFlagSet("bk ar invoice", flag.ExitOnError)
+	opts := &arInvoiceOpts{date: today()}
+	fset.StringVar(&opts.customer, "customer", "", "Customer ID or name.")
+	fset.Var(&dateFlag{&opts.date}, "date", "Invoice date. (format: yyyymmdd)")
+	fset.StringVar(&opts.amount, "amount", "", "Invoice amount. e.g. 110000")
+	fset.StringVar(&opts.memo, "memo", "", "Memo of the invoice.")
+	fset.IntVar(&opts.code, "account", bookkeeping.DefaultSalesCode, "Account code of the sales.")
+
+	return command{
+		name:        "invoice",
+		description: "Issue an invoice to a customer",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return arInvoice(opts, glOpts)
+		},
+	}
+}
+
+type arInvoiceOpts struct {
+	customer string
+	date     time.Time
+	amount   string
+	memo     string
+	code     int
+}
+
+func arInvoice(opts *arInvoiceOpts, glOpts *globalOpts) error {
+	if opts.customer == "" {
+		return fmt.Errorf("-customer is required")
+	}
+	if opts.amount == "" {
+		return fmt.Errorf("-amount is required")
+	}
+	amount, err := parseARAmount(opts.amount)
+	if err != nil {
+		return err
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	c, err := fetchCustomer(bk, opts.customer)
+	if err != nil {
+		return err
+	}
+
+	id, err := bk.IssueInvoice(bookkeeping.IssueInvoiceOpts{
+		CustomerID: c.ID,
+		Date:       opts.date,
+		Amount:     amount,
+		Memo:       opts.memo,
+		SalesCode:  opts.code,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "invoice %d issued to '%s'\n", id, c.Name)
+	return nil
+}
+
+func arInvoicesCmd() command {
+	fset := flag.NewFlagSet("bk ar invoices", flag.ExitOnError)
+	opts := &arInvoicesOpts{}
+	fset.StringVar(&opts.customer, "customer", "", "Customer ID or name filter.")
+	fset.BoolVar(&opts.open, "open", false, "List only invoices not fully paid.")
+
+	return command{
+		name:        "invoices",
+		description: "List invoices with paid and outstanding amounts",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return arInvoices(opts, glOpts)
+		},
+	}
+}
+
+type arInvoicesOpts struct {
+	customer string
+	open     bool
+}
+
+func arInvoices(opts *arInvoicesOpts, glOpts *globalOpts) error {
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	fopts := bookkeeping.FetchInvoicesOpts{Open: opts.open}
+	if opts.customer != "" {
+		c, err := fetchCustomer(bk, opts.customer)
+		if err != nil {
+			return err
+		}
+		fopts.CustomerID = c.ID
+	}
+
+	invs, err := bk.FetchInvoices(fopts)
+	if err != nil {
+		return err
+	}
+
+	return render(glOpts, report{
+		data:  invs,
+		text:  func(w io.Writer) { printInvoices(w, invs) },
+		table: func() [][]string { return invoicesTable(invs) },
+	})
+}
+
+func invoicesTable(invs []bookkeeping.Invoice) [][]string {
+	rows := [][]string{{"id", "date", "customer", "memo", "amount", "paid", "outstanding", "entry"}}
+	for _, inv := range invs {
+		rows = append(rows, []string{
+			strconv.Itoa(inv.ID), inv.Date.Time.Format("2006-01-02"), inv.CustomerName, inv.Memo,
+			strconv.Itoa(inv.Amount), strconv.Itoa(inv.Paid), strconv.Itoa(inv.Outstanding()), strconv.Itoa(inv.EntryID),
+		})
+	}
+	return rows
+}
+
+func printInvoices(w io.Writer, invs []bookkeeping.Invoice) {
+	fprintLFW(w, "id", 6)
+	fprintLFW(w, "date", 12)
+	fprintLFW(w, "customer", 20)
+	fprintLFW(w, "memo", 20)
+	fprintRFW(w, "amount", 12)
+	fprintRFW(w, "paid", 12)
+	fprintRFW(w, "outstanding", 12)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 94))
+
+	for _, inv := range invs {
+		fprintLFW(w, inv.ID, 6)
+		fprintLFW(w, inv.Date.Time.Format("2006/01/02"), 12)
+		fprintLFW(w, inv.CustomerName, 20)
+		fprintLFW(w, inv.Memo, 20)
+		fprintRFW(w, inv.Amount, 12)
+		fprintRFW(w, inv.Paid, 12)
+		fprintRFW(w, inv.Outstanding(), 12)
+		fmt.Fprintln(w)
+	}
+}
+
+func arPayCmd() command {
+	fset := flag.NewFlagSet("bk ar pay", flag.ExitOnError)
+	opts := &arPayOpts{date: today()}
+	fset.StringVar(&opts.customer, "customer", "", "Customer ID or name.")
+	fset.Var(&dateFlag{&opts.date}, "date", "Payment date. (format: yyyymmdd)")
+	fset.StringVar(&opts.amount, "amount", "", "Payment amount. e.g. 110000")
+	fset.StringVar(&opts.apply, "apply", "", "Comma separated <invoice ID>:<amount> to apply the payment to. (default the oldest open invoices)")
+	fset.IntVar(&opts.code, "account", bookkeeping.DefaultCashCode, "Account code which receives the payment.")
+
+	return command{
+		name:        "pay",
+		description: "Receive a payment from a customer and apply it to invoices",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return arPay(opts, glOpts)
+		},
+	}
+}
+
+type arPayOpts struct {
+	customer string
+	date     time.Time
+	amount   string
+	apply    string
+	code     int
+}
+
+func arPay(opts *arPayOpts, glOpts *globalOpts) error {
+	if opts.customer == "" {
+		return fmt.Errorf("-customer is required")
+	}
+	if opts.amount == "" {
+		return fmt.Errorf("-amount is required")
+	}
+	amount, err := parseARAmount(opts.amount)
+	if err != nil {
+		return err
+	}
+	apps, err := parseApplications(opts.apply)
+	if err != nil {
+		return err
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	c, err := fetchCustomer(bk, opts.customer)
+	if err != nil {
+		return err
+	}
+
+	id, err := bk.ReceivePayment(bookkeeping.ReceivePaymentOpts{
+		CustomerID:   c.ID,
+		Date:         opts.date,
+		Amount:       amount,
+		CashCode:     opts.code,
+		Applications: apps,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "entry %d posted, payment from '%s' applied\n", id, c.Name)
+	return nil
+}
+
+// parseApplications parses comma separated <invoice ID>:<amount>, such as '3:50000,4:20000'.
+func parseApplications(s string) ([]bookkeeping.PaymentApplication, error) {
+	apps := []bookkeeping.PaymentApplication{}
+	if strings.TrimSpace(s) == "" {
+		return apps, nil
+	}
+
+	for _, f := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(f), ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("want <invoice ID>:<amount>, but got '%s'", f)
+		}
+		id, err := strconv.Atoi(kv[0])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse '%s' as invoice ID", kv[0])
+		}
+		amount, err := parseARAmount(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, bookkeeping.PaymentApplication{InvoiceID: id, Amount: amount})
+	}
+	return apps, nil
+}
+
+func arAgingCmd() command {
+	fset := flag.NewFlagSet("bk ar aging", flag.ExitOnError)
+	opts := &arAgingOpts{date: today()}
+	fset.Var(&dateFlag{&opts.date}, "date", "Aging date. (format: yyyymmdd)")
+
+	return command{
+		name:        "aging",
+		description: "Show outstanding invoices per customer by age",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return arAging(opts, glOpts)
+		},
+	}
+}
+
+type arAgingOpts struct {
+	date time.Time
+}
+
+func arAging(opts *arAgingOpts, glOpts *globalOpts) error {
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	a, err := bk.FetchARAging(opts.date)
+	if err != nil {
+		return err
+	}
+
+	return render(glOpts, report{
+		data:  a,
+		text:  func(w io.Writer) { printARAging(w, a) },
+		table: func() [][]string { return arAgingTable(a) },
+	})
+}
+
+func arAgingTable(a bookkeeping.ARAging) [][]string {
+	header := append([]string{"customer_id", "customer"}, a.Buckets...)
+	rows := [][]string{append(header, "total")}
+	for _, ca := range append(a.Customers, a.Total) {
+		id, name := strconv.Itoa(ca.Customer.ID), ca.Customer.Name
+		if ca.Customer.ID == 0 {
+			id, name = "", "Total"
+		}
+		row := []string{id, name}
+		for _, v := range ca.Buckets {
+			row = append(row, strconv.Itoa(v))
+		}
+		rows = append(rows, append(row, strconv.Itoa(ca.Total)))
+	}
+	return rows
+}
+
+func printARAging(w io.Writer, a bookkeeping.ARAging) {
+	fmt.Fprintf(w, "Accounts Receivable Aging on %s (%s):\n", a.Date.Time.Format("2006/01/02"), bookkeeping.DefaultCurrency)
+	fmt.Fprintln(w)
+
+	fprintLFW(w, "customer", 24)
+	for _, b := range a.Buckets {
+		fprintRFW(w, b, 12)
+	}
+	fprintRFW(w, "total", 12)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 24+12*(len(a.Buckets)+1)))
+
+	printRow := func(name string, ca bookkeeping.CustomerAging) {
+		fprintLFW(w, name, 24)
+		for _, v := range ca.Buckets {
+			fprintRFW(w, v, 12)
+		}
+		fprintRFW(w, ca.Total, 12)
+		fmt.Fprintln(w)
+	}
+	for _, ca := range a.Customers {
+		printRow(ca.Customer.Name, ca)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 24+12*(len(a.Buckets)+1)))
+	printRow("Total", a.Total)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_parseApplications(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []bookkeeping.PaymentApplication
+		wantErr bool
+	}{
+		{"empty", "", []bookkeeping.PaymentApplication{}, false},
+		{"ok", "3:50000, 4:20000",
+			[]bookkeeping.PaymentApplication{{InvoiceID: 3, Amount: 50000}, {InvoiceID: 4, Amount: 20000}}, false},
+		{"error, missing amount", "3", nil, true},
+		{"error, wrong invoice ID", "x:100", nil, true},
+		{"error, foreign currency", "3:12.50USD", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseApplications(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseApplications() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseApplications() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findCustomer(t *testing.T) {
+	cs := []bookkeeping.Customer{{ID: 1, Name: "Acme"}, {ID: 2, Name: "42"}}
+
+	tests := []struct {
+		s       string
+		want    int
+		wantErr bool
+	}{
+		{"1", 1, false},
+		{"Acme", 1, false},
+		{"42", 2, false},
+		{"Beta", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := findCustomer(cs, tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("findCustomer(%s) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if got.ID != tt.want {
+			t.Errorf("findCustomer(%s) = %v, want %v", tt.s, got.ID, tt.want)
+		}
+	}
+}
@@ -0,0 +1,709 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+// defaultSuspenseCode is the account which statement lines without matching rule are posted to.
+const defaultSuspenseCode = 1190
+
+func bankCmd() command {
+	fset := flag.NewFlagSet("bk bank", flag.ExitOnError)
+
+	subcommands := []command{
+		bankImportCmd(),
+		bankReviewCmd(),
+		bankResolveCmd(),
+	}
+
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Subcommands:")
+		for _, cmd := range subcommands {
+			if cmd.fset == nil || cmd.fn == nil {
+				continue // skip not implemented
+			}
+
+			fmt.Fprintf(fset.Output(), "  %s:%s%s\n", cmd.name, strings.Repeat(" ", 12-len(cmd.name)), cmd.description)
+		}
+	}
+
+	return command{
+		name:          "bank",
+		description:   "Import bank statements and review unmatched lines",
+		hasSubcommand: true,
+		fset:          fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return subcmd("bk bank", subcommands, fset.Args(), glOpts)
+		},
+	}
+}
+
+// bankDir returns the directory of bank statement profiles and rules files in the data directory.
+func bankDir(dataDir string) string {
+	return filepath.Join(dataDir, "bank")
+}
+
+func bankImportCmd() command {
+	fset := flag.NewFlagSet("bk bank import", flag.ExitOnError)
+	opts := &bankImportOpts{}
+	fset.IntVar(&opts.code, "account", 0, "Account code of the bank account. e.g. 1110")
+	fset.StringVar(&opts.profile, "profile", "", "Name of the statement profile in <data-dir>/bank/<profile>.toml")
+	fset.StringVar(&opts.rules, "rules", "", "Rules file. (default <data-dir>/bank/<profile>.rules)")
+	fset.IntVar(&opts.suspense, "suspense", defaultSuspenseCode, "Account code for lines without matching rule.")
+	fset.BoolVar(&opts.dryRun, "dry-run", false, "Print the entries to be posted without posting.")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk bank import -account <code> -profile <name> [flags] <statement.csv>")
+		fset.PrintDefaults()
+		fmt.Fprintln(fset.Output())
+		fmt.Fprintln(fset.Output(), "Profile: columns of the statement CSV, by header name or 1-based number")
+		fmt.Fprintln(fset.Output(), `  date = "取引日"`)
+		fmt.Fprintln(fset.Output(), `  payee = "摘要"`)
+		fmt.Fprintln(fset.Output(), `  amount = "金額"          # or deposit = "お預り金額" and withdrawal = "お引出金額"`)
+		fmt.Fprintln(fset.Output(), `  balance = "残高"         # optional, checked to be continuous`)
+		fmt.Fprintln(fset.Output(), `  skip = "0"               # optional, lines to skip before the header`)
+		fmt.Fprintln(fset.Output())
+		fmt.Fprintln(fset.Output(), "Rules: the first rule whose regexp matches the payee gives the contra account")
+		fmt.Fprintln(fset.Output(), "  /JR東日本/ → 7300")
+	}
+
+	return command{
+		name:        "import",
+		description: "Import bank statement CSV file",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			if fset.NArg() != 1 {
+				fset.Usage()
+				return fmt.Errorf("statement file is required")
+			}
+			opts.file = fset.Arg(0)
+			return bankImport(opts, glOpts)
+		},
+	}
+}
+
+type bankImportOpts struct {
+	code     int
+	profile  string
+	rules    string
+	suspense int
+	dryRun   bool
+	file     string
+}
+
+func bankImport(opts *bankImportOpts, glOpts *globalOpts) error {
+	if opts.code == 0 {
+		return fmt.Errorf("-account is required")
+	}
+	if opts.profile == "" {
+		return fmt.Errorf("-profile is required")
+	}
+	if !bookNamePattern.MatchString(opts.profile) {
+		return fmt.Errorf("profile name may contain letters, numbers, '-' and '_', but got '%s'", opts.profile)
+	}
+
+	profile, err := loadBankProfile(filepath.Join(bankDir(glOpts.dataDir), opts.profile+".toml"))
+	if err != nil {
+		return err
+	}
+
+	rulesFile := opts.rules
+	if rulesFile == "" {
+		rulesFile = filepath.Join(bankDir(glOpts.dataDir), opts.profile+".rules")
+	}
+	rules, err := loadBankRules(rulesFile, opts.rules == "")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(opts.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stmt, err := parseStatement(f, profile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.file, err)
+	}
+
+	lines := make([]bookkeeping.BankLine, 0, len(stmt.lines))
+	unmatched := 0
+	for _, l := range stmt.lines {
+		l.line.Contra = matchBankRules(rules, l.line.Payee)
+		if l.line.Contra == 0 {
+			unmatched++
+		}
+		lines = append(lines, l.line)
+	}
+
+	if opts.dryRun {
+		printBankLines(glOpts.output, lines, opts.suspense)
+		return nil
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	ids, err := bk.ImportBankLines(opts.code, opts.suspense, lines)
+	if err != nil {
+		var entryErr *bookkeeping.EntryError
+		if errors.As(err, &entryErr) {
+			return fmt.Errorf("%s row %d: %w", opts.file, stmt.lines[entryErr.Index].row, entryErr.Err)
+		}
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "%d lines imported, %d to review\n", len(ids), unmatched)
+	if stmt.hasBalance && len(stmt.lines) > 0 {
+		last := stmt.lines[len(stmt.lines)-1]
+		fmt.Fprintf(glOpts.output, "statement balance %s on %s\n",
+			bookkeeping.FormatAmount(last.balance, bookkeeping.DefaultCurrency), last.line.Date.Format("2006/01/02"))
+	}
+	return nil
+}
+
+func printBankLines(w io.Writer, lines []bookkeeping.BankLine, suspense int) {
+	fprintLFW(w, "date", 12)
+	fprintLFW(w, "payee", 30)
+	fprintRFW(w, "amount", 15)
+	fprintRFW(w, "contra", 8)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 65))
+
+	for _, l := range lines {
+		contra := strconv.Itoa(l.Contra)
+		if l.Contra == 0 {
+			contra = strconv.Itoa(suspense) + "?"
+		}
+		fprintLFW(w, l.Date.Format("2006/01/02"), 12)
+		fprintLFW(w, l.Payee, 30)
+		fprintRFW(w, bookkeeping.FormatAmount(l.Amount, bookkeeping.DefaultCurrency), 15)
+		fprintRFW(w, contra, 8)
+		fmt.Fprintln(w)
+	}
+}
+
+// bankProfile is the columns of a bank statement CSV, which is a flat TOML file of string values like:
+//
+//	date = "取引日"
+//	payee = "摘要"
+//	deposit = "お預り金額"
+//	withdrawal = "お引出金額"
+//	balance = "残高"
+//
+// A column is a header name or a 1-based column number. The statement has a header row
+// if any of the columns is a header name. The amount is either of a signed amount column,
+// or a pair of deposit and withdrawal columns.
+type bankProfile struct {
+	date       string
+	payee      string
+	amount     string
+	deposit    string
+	withdrawal string
+	balance    string
+	// skip is the number of non-empty lines before the header row or the first line.
+	skip int
+}
+
+func loadBankProfile(path string) (bankProfile, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return bankProfile{}, fmt.Errorf("profile '%s' is not found", path)
+	}
+	if err != nil {
+		return bankProfile{}, err
+	}
+	defer f.Close()
+
+	p, err := parseBankProfile(f)
+	if err != nil {
+		return bankProfile{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+func parseBankProfile(r io.Reader) (bankProfile, error) {
+	p := bankProfile{}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return p, fmt.Errorf("line %d: want key = \"value\", but got '%s'", n, line)
+		}
+		key := strings.TrimSpace(kv[0])
+		value, err := parseConfigValue(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return p, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		switch key {
+		case "date":
+			p.date = value
+		case "payee":
+			p.payee = value
+		case "amount":
+			p.amount = value
+		case "deposit":
+			p.deposit = value
+		case "withdrawal":
+			p.withdrawal = value
+		case "balance":
+			p.balance = value
+		case "skip":
+			p.skip, err = strconv.Atoi(value)
+			if err != nil || p.skip < 0 {
+				return p, fmt.Errorf("line %d: skip must be a number of lines, but got '%s'", n, value)
+			}
+		default:
+			return p, fmt.Errorf("line %d: unknown key '%s'", n, key)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return p, err
+	}
+
+	if p.date == "" || p.payee == "" {
+		return p, fmt.Errorf("date and payee columns are required")
+	}
+	if (p.amount == "") == (p.deposit == "" && p.withdrawal == "") {
+		return p, fmt.Errorf("either amount column or deposit and withdrawal columns are required")
+	}
+	if p.amount == "" && (p.deposit == "" || p.withdrawal == "") {
+		return p, fmt.Errorf("deposit and withdrawal columns are required together")
+	}
+	return p, nil
+}
+
+// columns returns the profile columns in order of date, payee, amount, deposit, withdrawal and balance.
+func (p bankProfile) columns() []string {
+	return []string{p.date, p.payee, p.amount, p.deposit, p.withdrawal, p.balance}
+}
+
+// hasHeader reports whether any of the columns is a header name.
+func (p bankProfile) hasHeader() bool {
+	for _, c := range p.columns() {
+		if _, err := strconv.Atoi(c); c != "" && err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// columnIndexes returns the 0-based indexes of the profile columns, -1 for columns not in the profile.
+func (p bankProfile) columnIndexes(header []string) ([]int, error) {
+	cols := p.columns()
+	idx := make([]int, len(cols))
+	for i, c := range cols {
+		idx[i] = -1
+		if c == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(c); err == nil {
+			if n < 1 {
+				return nil, fmt.Errorf("column number must be 1 or greater, but got %d", n)
+			}
+			idx[i] = n - 1
+			continue
+		}
+		for j, h := range header {
+			if strings.TrimSpace(h) == c {
+				idx[i] = j
+				break
+			}
+		}
+		if idx[i] < 0 {
+			return nil, fmt.Errorf("column '%s' is not in the header", c)
+		}
+	}
+	return idx, nil
+}
+
+// statement is the lines of a bank statement in date order.
+type statement struct {
+	lines      []statementLine
+	hasBalance bool
+}
+
+type statementLine struct {
+	// row is the line number in the statement file.
+	row     int
+	line    bookkeeping.BankLine
+	balance int
+}
+
+// parseStatement parses a bank statement CSV in the profile format.
+// Lines in descending date order are reversed, and the balance column, if any, is checked to be continuous.
+func parseStatement(r io.Reader, p bankProfile) (statement, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	stmt := statement{lines: []statementLine{}, hasBalance: p.balance != ""}
+	var idx []int
+	var err error
+	if !p.hasHeader() {
+		if idx, err = p.columnIndexes(nil); err != nil {
+			return stmt, err
+		}
+	}
+
+	for n := 1; ; n++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stmt, err
+		}
+		if n <= p.skip {
+			continue
+		}
+		row, _ := cr.FieldPos(0)
+		if idx == nil {
+			if idx, err = p.columnIndexes(rec); err != nil {
+				return stmt, fmt.Errorf("row %d: %w", row, err)
+			}
+			continue
+		}
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+
+		l, err := parseStatementLine(rec, idx)
+		if err != nil {
+			return stmt, fmt.Errorf("row %d: %w", row, err)
+		}
+		l.row = row
+		stmt.lines = append(stmt.lines, l)
+	}
+
+	if n := len(stmt.lines); n > 1 && stmt.lines[0].line.Date.After(stmt.lines[n-1].line.Date) {
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			stmt.lines[i], stmt.lines[j] = stmt.lines[j], stmt.lines[i]
+		}
+	}
+
+	if stmt.hasBalance {
+		for i := 1; i < len(stmt.lines); i++ {
+			prev, l := stmt.lines[i-1], stmt.lines[i]
+			if prev.balance+l.line.Amount != l.balance {
+				return stmt, fmt.Errorf("row %d: balance %s does not follow balance %s of row %d and amount %s", l.row,
+					bookkeeping.FormatAmount(l.balance, bookkeeping.DefaultCurrency),
+					bookkeeping.FormatAmount(prev.balance, bookkeeping.DefaultCurrency), prev.row,
+					bookkeeping.FormatAmount(l.line.Amount, bookkeeping.DefaultCurrency))
+			}
+		}
+	}
+
+	return stmt, nil
+}
+
+func parseStatementLine(rec []string, idx []int) (statementLine, error) {
+	l := statementLine{}
+
+	col := func(i int) (string, error) {
+		if idx[i] < 0 {
+			return "", nil
+		}
+		if idx[i] >= len(rec) {
+			return "", fmt.Errorf("want at least %d columns, but got %d", idx[i]+1, len(rec))
+		}
+		return strings.TrimSpace(rec[idx[i]]), nil
+	}
+	v := make([]string, len(idx))
+	for i := range idx {
+		s, err := col(i)
+		if err != nil {
+			return l, err
+		}
+		v[i] = s
+	}
+	date, payee, amount, deposit, withdrawal, balance := v[0], v[1], v[2], v[3], v[4], v[5]
+
+	d, err := parseDate(date)
+	if err != nil {
+		return l, err
+	}
+	l.line.Date = d
+	l.line.Payee = payee
+
+	if idx[2] >= 0 {
+		if l.line.Amount, err = parseBankAmount(amount); err != nil {
+			return l, err
+		}
+	} else {
+		dep, err := parseBankAmount(deposit)
+		if err != nil {
+			return l, err
+		}
+		wd, err := parseBankAmount(withdrawal)
+		if err != nil {
+			return l, err
+		}
+		l.line.Amount = dep - wd
+	}
+	if l.line.Amount == 0 {
+		return l, fmt.Errorf("amount is zero")
+	}
+
+	if idx[5] >= 0 {
+		if l.balance, err = parseBankAmount(balance); err != nil {
+			return l, err
+		}
+	}
+
+	return l, nil
+}
+
+// bankAmountReplacer removes thousands separators and currency signs of statement amounts.
+var bankAmountReplacer = strings.NewReplacer(",", "", "¥", "", "￥", "", "円", "", " ", "")
+
+// parseBankAmount parses a signed statement amount in DefaultCurrency, such as '-1,200' or '¥3,000'.
+// An empty amount is zero.
+func parseBankAmount(s string) (int, error) {
+	s = bankAmountReplacer.Replace(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+
+	sign := 1
+	switch {
+	case strings.HasPrefix(s, "-"), strings.HasPrefix(s, "△"), strings.HasPrefix(s, "▲"):
+		sign = -1
+		s = strings.TrimLeft(s, "-△▲")
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	m, err := bookkeeping.ParseMoney(s)
+	if err != nil {
+		return 0, err
+	}
+	if m.Currency != bookkeeping.DefaultCurrency {
+		return 0, fmt.Errorf("amount must be in %s, but got '%s'", bookkeeping.DefaultCurrency, s)
+	}
+	return sign * m.Amount, nil
+}
+
+// bankRule maps payees matching the pattern to the contra account.
+type bankRule struct {
+	pattern *regexp.Regexp
+	code    int
+}
+
+// loadBankRules reads the rules file. A missing rules file is no rules if optional.
+func loadBankRules(path string, optional bool) ([]bankRule, error) {
+	f, err := os.Open(path)
+	if optional && errors.Is(err, os.ErrNotExist) {
+		return []bankRule{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := parseBankRules(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// parseBankRules parses rules of lines like '/JR東日本/ → 7300', where '->' is also accepted for '→'.
+// Lines starting with '#' are comments.
+func parseBankRules(r io.Reader) ([]bankRule, error) {
+	rules := []bankRule{}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		end := strings.LastIndex(line, "/")
+		if !strings.HasPrefix(line, "/") || end == 0 {
+			return nil, fmt.Errorf("line %d: want /regexp/ → code, but got '%s'", n, line)
+		}
+		pattern, err := regexp.Compile(line[1:end])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		rest := strings.TrimSpace(line[end+1:])
+		if i := strings.Index(rest, "#"); i >= 0 {
+			rest = strings.TrimSpace(rest[:i])
+		}
+		rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(rest, "→"), "->"))
+		code, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: cannot parse '%s' as account code", n, rest)
+		}
+
+		rules = append(rules, bankRule{pattern: pattern, code: code})
+	}
+
+	return rules, s.Err()
+}
+
+// matchBankRules returns the contra account of the first rule matching the payee, or 0 if none matches.
+func matchBankRules(rules []bankRule, payee string) int {
+	for _, r := range rules {
+		if r.pattern.MatchString(payee) {
+			return r.code
+		}
+	}
+	return 0
+}
+
+func bankReviewCmd() command {
+	fset := flag.NewFlagSet("bk bank review", flag.ExitOnError)
+	opts := &bankReviewOpts{}
+	fset.IntVar(&opts.code, "account", 0, "Account code filter of the bank account.")
+	fset.BoolVar(&opts.all, "all", false, "Include resolved lines.")
+
+	return command{
+		name:        "review",
+		description: "List bank statement lines posted to the suspense account",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return bankReview(opts, glOpts)
+		},
+	}
+}
+
+type bankReviewOpts struct {
+	code int
+	all  bool
+}
+
+func bankReview(opts *bankReviewOpts, glOpts *globalOpts) error {
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	reviews, err := bk.FetchBankReviews(bookkeeping.FetchBankReviewsOpts{Code: opts.code, IncludeResolved: opts.all})
+	if err != nil {
+		return err
+	}
+
+	return render(glOpts, report{
+		data:  reviews,
+		text:  func(w io.Writer) { printBankReviews(w, reviews) },
+		table: func() [][]string { return bankReviewsTable(reviews) },
+	})
+}
+
+func bankReviewsTable(reviews []bookkeeping.BankReview) [][]string {
+	rows := [][]string{{"id", "date", "account", "entry", "payee", "amount", "resolved_by"}}
+	for _, r := range reviews {
+		resolved := ""
+		if r.ResolvedBy > 0 {
+			resolved = strconv.Itoa(r.ResolvedBy)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(r.ID), r.Date.Time.Format("2006-01-02"), strconv.Itoa(r.Code), strconv.Itoa(r.EntryID),
+			r.Payee, bookkeeping.FormatAmount(r.Amount, bookkeeping.DefaultCurrency), resolved,
+		})
+	}
+	return rows
+}
+
+func printBankReviews(w io.Writer, reviews []bookkeeping.BankReview) {
+	fprintLFW(w, "id", 6)
+	fprintLFW(w, "date", 12)
+	fprintLFW(w, "account", 8)
+	fprintLFW(w, "payee", 30)
+	fprintRFW(w, "amount", 15)
+	fprintRFW(w, "resolved", 10)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 81))
+
+	for _, r := range reviews {
+		resolved := ""
+		if r.ResolvedBy > 0 {
+			resolved = strconv.Itoa(r.ResolvedBy)
+		}
+		fprintLFW(w, r.ID, 6)
+		fprintLFW(w, r.Date.Time.Format("2006/01/02"), 12)
+		fprintLFW(w, r.Code, 8)
+		fprintLFW(w, r.Payee, 30)
+		fprintRFW(w, bookkeeping.FormatAmount(r.Amount, bookkeeping.DefaultCurrency), 15)
+		fprintRFW(w, resolved, 10)
+		fmt.Fprintln(w)
+	}
+}
+
+func bankResolveCmd() command {
+	fset := flag.NewFlagSet("bk bank resolve", flag.ExitOnError)
+	opts := &bankResolveOpts{date: today()}
+	fset.IntVar(&opts.id, "id", 0, "ID of the bank review.")
+	fset.IntVar(&opts.code, "account", 0, "Account code to move the amount to from the suspense account.")
+	fset.Var(&dateFlag{&opts.date}, "date", "Post date of the resolving entry. (format: yyyymmdd)")
+
+	return command{
+		name:        "resolve",
+		description: "Move a reviewed line from the suspense account to an account",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return bankResolve(opts, glOpts)
+		},
+	}
+}
+
+type bankResolveOpts struct {
+	id   int
+	code int
+	date time.Time
+}
+
+func bankResolve(opts *bankResolveOpts, glOpts *globalOpts) error {
+	if opts.id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+	if opts.code == 0 {
+		return fmt.Errorf("-account is required")
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	id, err := bk.ResolveBankReview(opts.id, opts.code, opts.date)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "bank review %d resolved by entry %d\n", opts.id, id)
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_parseBankProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		toml       string
+		wantHeader bool
+		wantErr    bool
+	}{
+		{"ok, amount by header",
+			"date = \"取引日\"\npayee = \"摘要\"\namount = \"金額\"\nbalance = \"残高\"\n",
+			true, false,
+		},
+		{"ok, deposit and withdrawal by number",
+			"# statement without header\ndate = \"1\"\npayee = \"2\"\ndeposit = \"3\"\nwithdrawal = \"4\"\nskip = \"2\"\n",
+			false, false,
+		},
+		{"error, no amount",
+			"date = \"1\"\npayee = \"2\"\n",
+			false, true,
+		},
+		{"error, amount and deposit",
+			"date = \"1\"\npayee = \"2\"\namount = \"3\"\ndeposit = \"4\"\n",
+			false, true,
+		},
+		{"error, unknown key",
+			"date = \"1\"\npayee = \"2\"\namount = \"3\"\nmemo = \"4\"\n",
+			false, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBankProfile(strings.NewReader(tt.toml))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseBankProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.hasHeader() != tt.wantHeader {
+				t.Errorf("parseBankProfile() hasHeader = %v, want %v", got.hasHeader(), tt.wantHeader)
+			}
+		})
+	}
+}
+
+func Test_parseStatement(t *testing.T) {
+	headerProfile := bankProfile{date: "取引日", payee: "摘要", deposit: "お預り", withdrawal: "お引出", balance: "残高", skip: 1}
+	numberProfile := bankProfile{date: "1", payee: "2", amount: "3"}
+
+	tests := []struct {
+		name        string
+		profile     bankProfile
+		csv         string
+		wantAmounts []int
+		wantBalance int
+		wantErr     bool
+	}{
+		{"ok, header and balance",
+			headerProfile,
+			"口座番号 1234567\n" +
+				"取引日,摘要,お引出,お預り,残高\n" +
+				"2021/04/01,振込,,\"300,000\",\"1,300,000\"\n" +
+				"2021/04/02,JR東日本,\"1,200\",,\"1,298,800\"\n",
+			[]int{300000, -1200}, 1298800, false,
+		},
+		{"ok, descending order is reversed",
+			headerProfile,
+			"口座番号 1234567\n" +
+				"取引日,摘要,お引出,お預り,残高\n" +
+				"2021/04/02,JR東日本,\"1,200\",,\"1,298,800\"\n" +
+				"2021/04/01,振込,,\"300,000\",\"1,300,000\"\n",
+			[]int{300000, -1200}, 1298800, false,
+		},
+		{"ok, signed amount without header",
+			numberProfile,
+			"20210401,振込,¥300000\n20210402,JR東日本,-1200円\n",
+			[]int{300000, -1200}, 0, false,
+		},
+		{"error, balance is not continuous",
+			headerProfile,
+			"口座番号 1234567\n" +
+				"取引日,摘要,お引出,お預り,残高\n" +
+				"2021/04/01,振込,,300000,1300000\n" +
+				"2021/04/02,JR東日本,1200,,1290000\n",
+			nil, 0, true,
+		},
+		{"error, missing header column",
+			bankProfile{date: "日付", payee: "摘要", amount: "金額"},
+			"取引日,摘要,金額\n2021/04/01,振込,300000\n",
+			nil, 0, true,
+		},
+		{"error, wrong amount",
+			numberProfile,
+			"20210401,振込,300USD\n",
+			nil, 0, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatement(strings.NewReader(tt.csv), tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseStatement() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if len(got.lines) != len(tt.wantAmounts) {
+				t.Fatalf("parseStatement() got %v lines, want %v", len(got.lines), len(tt.wantAmounts))
+			}
+			for i, want := range tt.wantAmounts {
+				if got.lines[i].line.Amount != want {
+					t.Errorf("parseStatement() line %d amount = %v, want %v", i, got.lines[i].line.Amount, want)
+				}
+			}
+			if last := got.lines[len(got.lines)-1]; last.balance != tt.wantBalance {
+				t.Errorf("parseStatement() closing balance = %v, want %v", last.balance, tt.wantBalance)
+			}
+		})
+	}
+}
+
+func Test_parseBankRules(t *testing.T) {
+	rules, err := parseBankRules(strings.NewReader(
+		"# transport\n" +
+			"/JR東日本/ → 7300\n" +
+			"/^振込 / -> 4100  # sales\n" +
+			"/a/b/ 7300\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("parseBankRules() got %v rules, want 3", len(rules))
+	}
+
+	tests := []struct {
+		payee string
+		want  int
+	}{
+		{"JR東日本 モバイルSuica", 7300},
+		{"振込 カブシキガイシャエー", 4100},
+		{"xa/by", 7300},
+		{"カード引落", 0},
+	}
+	for _, tt := range tests {
+		if got := matchBankRules(rules, tt.payee); got != tt.want {
+			t.Errorf("matchBankRules(%s) = %v, want %v", tt.payee, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"JR東日本 → 7300\n", "/JR東日本/ → expense\n", "/[/ → 7300\n"} {
+		if _, err := parseBankRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("parseBankRules(%q) must fail", bad)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+var bookNamePattern = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+func validateBookName(name string) error {
+	if !bookNamePattern.MatchString(name) {
+		return fmt.Errorf("book name may contain letters, numbers, '-' and '_', but got '%s'", name)
+	}
+	return nil
+}
+
+// bookPath returns the database file path of the book in the data directory.
+func bookPath(dataDir, book string) string {
+	return filepath.Join(dataDir, book+bookExt)
+}
+
+// openBook opens the database of the selected book, and applies the pending migrations.
+// Only init and book create create a book, so that a mistyped book name never creates an empty book.
+func openBook(glOpts *globalOpts) (*bookkeeping.DB, error) {
+	if _, err := os.Stat(glOpts.dbPath()); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("book '%s' does not exist", glOpts.book)
+	}
+	return bookkeeping.NewDB(glOpts.dbPath())
+}
+
+// listBooks returns the names of books in the data directory in alphabetical order.
+func listBooks(dataDir string) ([]string, error) {
+	files, err := os.ReadDir(dataDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	books := []string{}
+	for _, f := range files {
+		name := strings.TrimSuffix(f.Name(), bookExt)
+		if f.IsDir() || filepath.Ext(f.Name()) != bookExt || validateBookName(name) != nil {
+			continue
+		}
+		books = append(books, name)
+	}
+	sort.Strings(books)
+	return books, nil
+}
+
+func bookCmd() command {
+	fset := flag.NewFlagSet("bk book", flag.ExitOnError)
+
+	subcommands := []command{
+		bookListCmd(),
+		bookCreateCmd(),
+		bookDeleteCmd(),
+	}
+
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Subcommands:")
+		for _, cmd := range subcommands {
+			if cmd.fset == nil || cmd.fn == nil {
+				continue // skip not implemented
+			}
+
+			fmt.Fprintf(fset.Output(), "  %s:%s%s\n", cmd.name, strings.Repeat(" ", 12-len(cmd.name)), cmd.description)
+		}
+	}
+
+	return command{
+		name:          "book",
+		description:   "Manage books",
+		hasSubcommand: true,
+		fset:          fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return subcmd("bk book", subcommands, fset.Args(), glOpts)
+		},
+	}
+}
+
+func bookListCmd() command {
+	fset := flag.NewFlagSet("bk book list", flag.ExitOnError)
+
+	return command{
+		name:        "list",
+		description: "List books",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return bookList(glOpts)
+		},
+	}
+}
+
+func bookList(glOpts *globalOpts) error {
+	books, err := listBooks(glOpts.dataDir)
+	if err != nil {
+		return err
+	}
+
+	return render(glOpts, report{
+		data:  books,
+		text:  func(w io.Writer) { printBooks(w, books, glOpts.book) },
+		table: func() [][]string { return booksTable(books, glOpts.book) },
+	})
+}
+
+func booksTable(books []string, selected string) [][]string {
+	rows := [][]string{{"book", "selected"}}
+	for _, b := range books {
+		rows = append(rows, []string{b, fmt.Sprint(b == selected)})
+	}
+	return rows
+}
+
+func printBooks(w io.Writer, books []string, selected string) {
+	for _, b := range books {
+		mark := " "
+		if b == selected {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s\n", mark, b)
+	}
+}
+
+func bookCreateCmd() command {
+	fset := flag.NewFlagSet("bk book create", flag.ExitOnError)
+	template := fset.String("template", bookkeeping.DefaultAccountTemplate, "Chart of accounts template of the new book. (see bk init -list)")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk book create [flags] <name>")
+		fset.PrintDefaults()
+	}
+
+	return command{
+		name:        "create",
+		description: "Create book",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			if fset.NArg() != 1 {
+				fset.Usage()
+				return fmt.Errorf("book name is required")
+			}
+			return bookCreate(fset.Arg(0), *template, glOpts)
+		},
+	}
+}
+
+func bookCreate(name string, template string, glOpts *globalOpts) error {
+	if err := validateBookName(name); err != nil {
+		return err
+	}
+
+	path := bookPath(glOpts.dataDir, name)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("book '%s' already exists", name)
+	}
+
+	db, err := bookkeeping.CreateDB(path, template)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	fmt.Fprintf(glOpts.output, "book '%s' created\n", name)
+	return nil
+}
+
+func bookDeleteCmd() command {
+	fset := flag.NewFlagSet("bk book delete", flag.ExitOnError)
+	opts := &bookDeleteOpts{}
+	fset.BoolVar(&opts.yes, "yes", false, "Delete without confirmation.")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk book delete [flags] <name>")
+		fset.PrintDefaults()
+	}
+
+	return command{
+		name:        "delete",
+		description: "Delete book",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			if fset.NArg() != 1 {
+				fset.Usage()
+				return fmt.Errorf("book name is required")
+			}
+			opts.name = fset.Arg(0)
+			return bookDelete(opts, glOpts)
+		},
+	}
+}
+
+type bookDeleteOpts struct {
+	name string
+	yes  bool
+}
+
+func bookDelete(opts *bookDeleteOpts, glOpts *globalOpts) error {
+	if err := validateBookName(opts.name); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(bookPath(glOpts.dataDir, opts.name)); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("book '%s' is not found", opts.name)
+	}
+
+	return deleteBook(glOpts, opts.name, opts.yes)
+}
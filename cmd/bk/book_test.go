@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_validateBookName(t *testing.T) {
+	tests := []struct {
+		name    string
+		book    string
+		wantErr bool
+	}{
+		{"ok", "acme-corp_2", false},
+		{"error, empty", "", true},
+		{"error, path", "../acme", true},
+		{"error, space", "acme corp", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBookName(tt.book); (err != nil) != tt.wantErr {
+				t.Errorf("validateBookName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_listBooks(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"bookkeeping.db", "acme.db", "notes.txt", "bookkeeping.db.bak"} {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := listBooks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"acme", "bookkeeping"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listBooks() = %v, want %v", got, want)
+	}
+
+	got, err = listBooks(filepath.Join(dir, "missing"))
+	if err != nil || len(got) != 0 {
+		t.Errorf("listBooks() of missing directory must be empty, but got %v, %v", got, err)
+	}
+}
+
+func Test_openBook(t *testing.T) {
+	glOpts := &globalOpts{dataDir: t.TempDir(), book: "acmee"}
+
+	if _, err := openBook(glOpts); err == nil || err.Error() != "book 'acmee' does not exist" {
+		t.Errorf("openBook() of missing book must return error, but got %v", err)
+	}
+	if _, err := os.Stat(glOpts.dbPath()); !os.IsNotExist(err) {
+		t.Errorf("openBook() must not create the missing book, but got %v", err)
+	}
+
+	glOpts.output = io.Discard
+	if err := bookCreate("acmee", bookkeeping.DefaultAccountTemplate, glOpts); err != nil {
+		t.Fatal(err)
+	}
+	db, err := openBook(glOpts)
+	if err != nil {
+		t.Fatalf("openBook() error = %v", err)
+	}
+	db.Close()
+}
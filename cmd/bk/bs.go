@@ -4,7 +4,6 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -15,6 +14,7 @@ func bsCmd() command {
 	fset := flag.NewFlagSet("bk bs", flag.ExitOnError)
 	opts := &bsOpts{}
 	fset.Var(&dateFlag{&opts.Date}, "date", "date of Balance Sheet. (format: yyyymmdd)")
+	fset.BoolVar(&opts.Detail, "detail", false, "Show balance of each account.")
 
 	return command{
 		name:        "bs",
@@ -28,88 +28,161 @@ func bsCmd() command {
 }
 
 type bsOpts struct {
-	Date time.Time
+	Date   time.Time
+	Detail bool
 }
 
 func bs(opts *bsOpts, glOpts *globalOpts) error {
 
-	db, err := bookkeeping.NewDB(filepath.Join(glOpts.dataDir, databaseName))
+	db, err := openBook(glOpts)
 	if err != nil {
 		return err
 	}
 	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
 
 	fetchBsOpts := bookkeeping.FetchBSOpts{
 		Date: opts.Date,
 	}
 
+	if opts.Detail {
+		bs, err := bk.FetchBSDetail(fetchBsOpts)
+		if err != nil {
+			return err
+		}
+
+		return render(glOpts, report{
+			data:  bs,
+			text:  func(w io.Writer) { printBSDetail(w, glOpts.locale, bs) },
+			table: func() [][]string { return bsDetailTable(bs) },
+		})
+	}
+
 	bs, err := bk.FetchBS(fetchBsOpts)
 	if err != nil {
 		return err
 	}
 
-	printBS(glOpts.output, bs)
+	return render(glOpts, report{
+		data:  bs,
+		text:  func(w io.Writer) { printBS(w, glOpts.locale, bs) },
+		table: func() [][]string { return bsTable(bs) },
+	})
+}
+
+func bsTable(bs bookkeeping.BS) [][]string {
+	return [][]string{
+		{"description", "amount"},
+		{"Total Current Assets", bookkeeping.FormatAmount(bs.TotalCurrentAssets, bs.Currency)},
+		{"Total Noncurrent Assets", bookkeeping.FormatAmount(bs.TotalNoncurrentAssets, bs.Currency)},
+		{"Total Assets", bookkeeping.FormatAmount(bs.TotalAssets, bs.Currency)},
+		{"Total Current Liabilities", bookkeeping.FormatAmount(bs.TotalCurrentLiabilities, bs.Currency)},
+		{"Total Noncurrent Liabilities", bookkeeping.FormatAmount(bs.TotalNoncurrentLiabilities, bs.Currency)},
+		{"Total Liabilities", bookkeeping.FormatAmount(bs.TotalLiabilities, bs.Currency)},
+		{"Owner's Capital", bookkeeping.FormatAmount(bs.OwnersCapital, bs.Currency)},
+		{"Capital Surplus", bookkeeping.FormatAmount(bs.CapitalSurplus, bs.Currency)},
+		{"Retained Earnings", bookkeeping.FormatAmount(bs.RetainedErnings, bs.Currency)},
+		{"Total Equity", bookkeeping.FormatAmount(bs.TotalEquity, bs.Currency)},
+		{"Total Liabilities and Equity", bookkeeping.FormatAmount(bs.TotalLiabilitiesAndEquity, bs.Currency)},
+	}
+}
 
-	return nil
+func bsDetailTable(bs bookkeeping.BSDetail) [][]string {
+	rows := [][]string{{"section", "section name", "code", "name", "amount"}}
+	for _, sec := range bs.Sections {
+		rows = append(rows, sectionRows(sec, bs.Currency)...)
+	}
+	return rows
 }
 
-func printBS(w io.Writer, bs bookkeeping.BS) {
-	fmt.Fprintln(w, "Balance Sheet:")
+func printBS(w io.Writer, lang string, bs bookkeeping.BS) {
+	fmt.Fprintln(w, label(lang, "Balance Sheet")+":")
 	fmt.Fprintln(w)
 
 	indent := strings.Repeat(" ", 10)
 
-	fmt.Fprintln(w, "Assets:")
-	fprintLFW(w, "description", 45)
-	fprintRFW(w, "amount", 20)
+	fmt.Fprintln(w, label(lang, "Assets")+":")
+	fprintLFW(w, label(lang, "description"), 45)
+	fprintRFW(w, label(lang, "amount")+" ("+bs.Currency+")", 20)
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, strings.Repeat("-", 65))
 
-	fprintLFW(w, indent+"Total Current Assets", 45)
-	fprintRFW(w, bs.TotalCurrentAssets, 20)
+	fprintLFW(w, indent+label(lang, "Total Current Assets"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalCurrentAssets, bs.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, indent+"Total Noncurrent Assets", 45)
-	fprintRFW(w, bs.TotalNoncurrentAssets, 20)
+	fprintLFW(w, indent+label(lang, "Total Noncurrent Assets"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalNoncurrentAssets, bs.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Total Assets", 45)
-	fprintRFW(w, bs.TotalAssets, 20)
+	fprintLFW(w, label(lang, "Total Assets"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalAssets, bs.Currency), 20)
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w)
 
-	fmt.Fprintln(w, "Liabilities:")
-	fprintLFW(w, "description", 45)
-	fprintRFW(w, "amount", 20)
+	fmt.Fprintln(w, label(lang, "Liabilities")+":")
+	fprintLFW(w, label(lang, "description"), 45)
+	fprintRFW(w, label(lang, "amount")+" ("+bs.Currency+")", 20)
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, strings.Repeat("-", 65))
 
-	fprintLFW(w, "Total Liabilities", 45)
-	fprintRFW(w, bs.TotalLiabilities, 20)
+	fprintLFW(w, indent+label(lang, "Total Current Liabilities"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalCurrentLiabilities, bs.Currency), 20)
+	fmt.Fprintln(w)
+
+	fprintLFW(w, indent+label(lang, "Total Noncurrent Liabilities"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalNoncurrentLiabilities, bs.Currency), 20)
 	fmt.Fprintln(w)
 
+	fprintLFW(w, label(lang, "Total Liabilities"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalLiabilities, bs.Currency), 20)
 	fmt.Fprintln(w)
 
-	fmt.Fprintln(w, "Equity:")
-	fprintLFW(w, "description", 45)
-	fprintRFW(w, "amount", 20)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, label(lang, "Equity")+":")
+	fprintLFW(w, label(lang, "description"), 45)
+	fprintRFW(w, label(lang, "amount")+" ("+bs.Currency+")", 20)
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, strings.Repeat("-", 65))
 
-	fprintLFW(w, indent+"Owner's Capital", 45)
-	fprintRFW(w, bs.OwnersCapital, 20)
+	fprintLFW(w, indent+label(lang, "Owner's Capital"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.OwnersCapital, bs.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, indent+"Retained Earnings", 45)
-	fprintRFW(w, bs.RetainedErnings, 20)
+	fprintLFW(w, indent+label(lang, "Capital Surplus"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.CapitalSurplus, bs.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Total Equity", 45)
-	fprintRFW(w, bs.TotalEquity, 20)
+	fprintLFW(w, indent+label(lang, "Retained Earnings"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.RetainedErnings, bs.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Total Liabilities and Equity", 45)
-	fprintRFW(w, bs.TotalLiabilitiesAndEquity, 20)
+	fprintLFW(w, label(lang, "Total Equity"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalEquity, bs.Currency), 20)
+	fmt.Fprintln(w)
+
+	fprintLFW(w, label(lang, "Total Liabilities and Equity"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalLiabilitiesAndEquity, bs.Currency), 20)
+	fmt.Fprintln(w)
+}
+
+func printBSDetail(w io.Writer, lang string, bs bookkeeping.BSDetail) {
+	fmt.Fprintln(w, label(lang, "Balance Sheet")+":")
+	fmt.Fprintln(w)
+
+	fprintLFW(w, label(lang, "description"), 45)
+	fprintRFW(w, label(lang, "amount")+" ("+bs.Currency+")", 20)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 65))
+
+	for _, sec := range bs.Sections {
+		printSection(w, lang, sec, bs.Currency, 0)
+		fmt.Fprintln(w)
+	}
+
+	fprintLFW(w, label(lang, "Total Liabilities and Equity"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(bs.TotalLiabilitiesAndEquity, bs.Currency), 20)
 	fmt.Fprintln(w)
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func closeCmd() command {
+	fset := flag.NewFlagSet("bk close", flag.ExitOnError)
+	opts := &closeOpts{}
+	fset.IntVar(&opts.year, "year", 0, "Fiscal year to close, which is the year the fiscal year starts in.")
+
+	return command{
+		name:        "close",
+		description: "Close fiscal year",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return closeYear(opts, glOpts)
+		},
+	}
+}
+
+type closeOpts struct {
+	year int
+}
+
+func closeYear(opts *closeOpts, glOpts *globalOpts) error {
+	if opts.year == 0 {
+		return fmt.Errorf("-year is required")
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	id, err := bk.Close(opts.year)
+	if err != nil {
+		return err
+	}
+
+	closed, err := bk.ClosedThrough()
+	if err != nil {
+		return err
+	}
+
+	if id > 0 {
+		fmt.Fprintf(glOpts.output, "entry %d posted\n", id)
+	}
+	fmt.Fprintf(glOpts.output, "fiscal year %d closed through %s\n", opts.year, closed.Format("2006/01/02"))
+	return nil
+}
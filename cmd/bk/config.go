@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const configFileName = "config.toml"
+
+// config is the settings in the config file, which is a flat TOML file of string values like:
+//
+//	data_dir = "/srv/bookkeeping"
+//	book = "acme"
+//	format = "json"
+//	locale = "en"
+//
+// Flags and environment variables take precedence over the config file.
+type config struct {
+	dataDir string
+	book    string
+	format  string
+	locale  string
+}
+
+// loadConfig reads the config file. A missing config file is an empty config.
+func loadConfig(path string) (config, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config{}, nil
+	}
+	if err != nil {
+		return config{}, err
+	}
+	defer f.Close()
+
+	c, err := parseConfig(f)
+	if err != nil {
+		return config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+func parseConfig(r io.Reader) (config, error) {
+	c := config{}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return c, fmt.Errorf("line %d: want key = \"value\", but got '%s'", n, line)
+		}
+		key := strings.TrimSpace(kv[0])
+		value, err := parseConfigValue(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return c, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		switch key {
+		case "data_dir":
+			c.dataDir = value
+		case "book":
+			c.book = value
+		case "format":
+			c.format = value
+		case "locale":
+			c.locale = value
+		default:
+			return c, fmt.Errorf("line %d: unknown key '%s'", n, key)
+		}
+	}
+
+	return c, s.Err()
+}
+
+// parseConfigValue parses a quoted string value, followed by an optional comment.
+func parseConfigValue(v string) (string, error) {
+	if !strings.HasPrefix(v, `"`) {
+		return "", fmt.Errorf("value must be a quoted string, but got '%s'", v)
+	}
+
+	end := 1
+	for ; end < len(v); end++ {
+		if v[end] == '\\' {
+			end++
+			continue
+		}
+		if v[end] == '"' {
+			break
+		}
+	}
+	if end >= len(v) {
+		return "", fmt.Errorf("unterminated string '%s'", v)
+	}
+
+	if rest := strings.TrimSpace(v[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected '%s' after value", rest)
+	}
+
+	return strconv.Unquote(v[:end+1])
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
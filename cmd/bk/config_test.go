@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_parseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    string
+		want    config
+		wantErr bool
+	}{
+		{"ok",
+			"# bookkeeping\n" +
+				"data_dir = \"/srv/bookkeeping\"\n" +
+				"book = \"acme\" # default book\n" +
+				"\n" +
+				"format = \"json\"\n" +
+				"locale = \"en\"\n",
+			config{dataDir: "/srv/bookkeeping", book: "acme", format: "json", locale: "en"}, false,
+		},
+		{"error, unknown key", "currency = \"USD\"\n", config{}, true},
+		{"error, not quoted", "book = acme\n", config{}, true},
+		{"error, unterminated", "book = \"acme\n", config{}, true},
+		{"error, no value", "book\n", config{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConfig(strings.NewReader(tt.conf))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func dbCmd() command {
+	fset := flag.NewFlagSet("bk db", flag.ExitOnError)
+
+	subcommands := []command{
+		dbMigrateCmd(),
+		dbStatusCmd(),
+		dbBackupCmd(),
+		dbRestoreCmd(),
+	}
+
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Subcommands:")
+		for _, cmd := range subcommands {
+			if cmd.fset == nil || cmd.fn == nil {
+				continue // skip not implemented
+			}
+
+			fmt.Fprintf(fset.Output(), "  %s:%s%s\n", cmd.name, strings.Repeat(" ", 12-len(cmd.name)), cmd.description)
+		}
+	}
+
+	return command{
+		name:          "db",
+		description:   "Manage database",
+		hasSubcommand: true,
+		fset:          fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return subcmd("bk db", subcommands, fset.Args(), glOpts)
+		},
+	}
+}
+
+func dbMigrateCmd() command {
+	fset := flag.NewFlagSet("bk db migrate", flag.ExitOnError)
+
+	return command{
+		name:        "migrate",
+		description: "Apply pending schema migrations",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return dbMigrate(glOpts)
+		},
+	}
+}
+
+func dbMigrate(glOpts *globalOpts) error {
+
+	db, err := bookkeeping.OpenDB(glOpts.dbPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	applied, err := db.Migrate()
+	for _, m := range applied {
+		fmt.Fprintf(glOpts.output, "applied %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	v, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(glOpts.output, "schema version: %d\n", v)
+	return nil
+}
+
+func dbStatusCmd() command {
+	fset := flag.NewFlagSet("bk db status", flag.ExitOnError)
+
+	return command{
+		name:        "status",
+		description: "Show schema migrations",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return dbStatus(glOpts)
+		},
+	}
+}
+
+func dbStatus(glOpts *globalOpts) error {
+
+	db, err := bookkeeping.OpenDB(glOpts.dbPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ms, err := db.Migrations()
+	if err != nil {
+		return err
+	}
+
+	return render(glOpts, report{
+		data:  ms,
+		text:  func(w io.Writer) { printMigrations(w, ms) },
+		table: func() [][]string { return migrationsTable(ms) },
+	})
+}
+
+func migrationStatus(m bookkeeping.Migration) (string, string) {
+	if m.Pending() {
+		return "pending", ""
+	}
+	return "applied", m.AppliedAt.Local().Format("2006/01/02 15:04:05")
+}
+
+func migrationsTable(ms []bookkeeping.Migration) [][]string {
+	rows := [][]string{{"version", "name", "status", "applied_at"}}
+	for _, m := range ms {
+		status, at := migrationStatus(m)
+		rows = append(rows, []string{strconv.Itoa(m.Version), m.Name, status, at})
+	}
+	return rows
+}
+
+func printMigrations(w io.Writer, ms []bookkeeping.Migration) {
+	fprintLFW(w, "version", 10)
+	fprintLFW(w, "name", 30)
+	fprintLFW(w, "status", 10)
+	fprintLFW(w, "applied at", 20)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 70))
+
+	for _, m := range ms {
+		status, at := migrationStatus(m)
+		fprintLFW(w, fmt.Sprintf("%04d", m.Version), 10)
+		fprintLFW(w, m.Name, 30)
+		fprintLFW(w, status, 10)
+		fprintLFW(w, at, 20)
+		fmt.Fprintln(w)
+	}
+}
+
+func dbBackupCmd() command {
+	fset := flag.NewFlagSet("bk db backup", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk db backup [file]")
+		fmt.Fprintln(fset.Output())
+		fmt.Fprintln(fset.Output(), "Without file, the backup is written into the backups directory in the data directory.")
+	}
+
+	return command{
+		name:        "backup",
+		description: "Backup database of the book",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			if fset.NArg() > 1 {
+				fset.Usage()
+				return fmt.Errorf("too many arguments")
+			}
+			return dbBackup(fset.Arg(0), glOpts)
+		},
+	}
+}
+
+func dbBackup(file string, glOpts *globalOpts) error {
+
+	db, err := bookkeeping.OpenDB(glOpts.dbPath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if file == "" {
+		file = backupPath(glOpts.dataDir, glOpts.book, time.Now())
+	}
+	if err := db.Backup(file); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "backup written to %s\n", file)
+	return nil
+}
+
+// backupPath returns the path of a timestamped backup file of the book.
+func backupPath(dataDir, book string, t time.Time) string {
+	return filepath.Join(dataDir, "backups", book+"-"+t.Format("20060102-150405.000")+bookExt)
+}
+
+func dbRestoreCmd() command {
+	fset := flag.NewFlagSet("bk db restore", flag.ExitOnError)
+	opts := &dbRestoreOpts{}
+	fset.BoolVar(&opts.yes, "yes", false, "Restore without confirmation.")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk db restore [flags] <file>")
+		fset.PrintDefaults()
+	}
+
+	return command{
+		name:        "restore",
+		description: "Restore database of the book from backup",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			if fset.NArg() != 1 {
+				fset.Usage()
+				return fmt.Errorf("backup file is required")
+			}
+			opts.file = fset.Arg(0)
+			return dbRestore(opts, glOpts)
+		},
+	}
+}
+
+type dbRestoreOpts struct {
+	file string
+	yes  bool
+}
+
+func dbRestore(opts *dbRestoreOpts, glOpts *globalOpts) error {
+	path := glOpts.dbPath()
+
+	if _, err := os.Stat(path); err == nil {
+		ok, err := confirm(glOpts, fmt.Sprintf("Replace book '%s' (%s) with %s?", glOpts.book, path, opts.file), opts.yes)
+		if err != nil || !ok {
+			return err
+		}
+
+		db, err := bookkeeping.OpenDB(path)
+		if err != nil {
+			return err
+		}
+		backup := backupPath(glOpts.dataDir, glOpts.book, time.Now())
+		err = db.Backup(backup)
+		db.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(glOpts.output, "backup written to %s\n", backup)
+	}
+
+	if err := bookkeeping.RestoreDB(opts.file, path); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "book '%s' restored from %s\n", glOpts.book, opts.file)
+	return nil
+}
+
+// confirm asks the question and returns true if the answer is yes. It returns true without asking if yes is true.
+func confirm(glOpts *globalOpts, question string, yes bool) (bool, error) {
+	if yes {
+		return true, nil
+	}
+
+	fmt.Fprintf(glOpts.output, "%s [y/N]: ", question)
+	answer, err := bufio.NewReader(glOpts.input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	fmt.Fprintln(glOpts.output, "canceled")
+	return false, nil
+}
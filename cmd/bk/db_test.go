@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_confirm(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		yes    bool
+		want   bool
+		prompt bool
+	}{
+		{"yes", "y\n", false, true, true},
+		{"yes, long", " Yes \n", false, true, true},
+		{"no", "n\n", false, false, true},
+		{"no, empty", "", false, false, true},
+		{"-yes", "", true, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			glOpts := &globalOpts{input: strings.NewReader(tt.input), output: out}
+
+			got, err := confirm(glOpts, "Delete?", tt.yes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("confirm() = %v, want %v", got, tt.want)
+			}
+			if prompted := strings.Contains(out.String(), "Delete? [y/N]"); prompted != tt.prompt {
+				t.Errorf("confirm() prompted = %v, want %v", prompted, tt.prompt)
+			}
+		})
+	}
+}
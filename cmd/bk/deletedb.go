@@ -2,36 +2,61 @@ package main
 
 import (
 	"flag"
-	"path/filepath"
+	"fmt"
+	"time"
 
 	"github.com/yoskeoka/bookkeeping"
 )
 
 func deletedbCmd() command {
 	fset := flag.NewFlagSet("bk deletedb", flag.ExitOnError)
+	opts := &deletedbOpts{}
+	fset.BoolVar(&opts.yes, "yes", false, "Delete without confirmation.")
 
 	return command{
 		name:        "deletedb",
-		description: "Delete all journals",
+		description: "Delete database of the book, including accounts and journals",
 		fset:        fset,
 		fn: func(args []string, glOpts *globalOpts) error {
 			fset.Parse(args)
-			return deletedb(glOpts)
+			return deletedb(opts, glOpts)
 		},
 	}
 }
 
-func deletedb(glOpts *globalOpts) error {
+type deletedbOpts struct {
+	yes bool
+}
+
+func deletedb(opts *deletedbOpts, glOpts *globalOpts) error {
+	return deleteBook(glOpts, glOpts.book, opts.yes)
+}
 
-	db, err := bookkeeping.NewDB(filepath.Join(glOpts.dataDir, databaseName))
+// deleteBook deletes the database of the book after confirmation, writing a backup before deleting.
+func deleteBook(glOpts *globalOpts, book string, yes bool) error {
+	path := bookPath(glOpts.dataDir, book)
+
+	db, err := bookkeeping.OpenDB(path)
 	if err != nil {
 		return err
 	}
+	defer db.Close()
 
-	err = db.Delete()
-	if err != nil {
+	ok, err := confirm(glOpts, fmt.Sprintf("Delete book '%s' (%s) including all accounts and journals?", book, path), yes)
+	if err != nil || !ok {
+		return err
+	}
+
+	backup := backupPath(glOpts.dataDir, book, time.Now())
+	if err := db.Backup(backup); err != nil {
+		return err
+	}
+	fmt.Fprintf(glOpts.output, "backup written to %s\n", backup)
+
+	if err := db.Delete(); err != nil {
 		return err
 	}
 
+	fmt.Fprintf(glOpts.output, "book '%s' deleted\n", book)
 	return nil
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func entryCmd() command {
+	fset := flag.NewFlagSet("bk entry", flag.ExitOnError)
+	opts := &entryOpts{}
+	fset.IntVar(&opts.id, "id", 0, "Entry ID.")
+
+	return command{
+		name:        "entry",
+		description: "Show journal entry",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return entry(opts, glOpts)
+		},
+	}
+}
+
+type entryOpts struct {
+	id int
+}
+
+func entry(opts *entryOpts, glOpts *globalOpts) error {
+	if opts.id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
+
+	e, err := bk.FetchEntry(opts.id)
+	if err != nil {
+		return err
+	}
+
+	return render(glOpts, report{
+		data:  e,
+		text:  func(w io.Writer) { printEntry(w, e) },
+		table: func() [][]string { return entryTable(e) },
+	})
+}
+
+func entryTable(e bookkeeping.Entry) [][]string {
+	rows := [][]string{{"entry", "date", "code", "name", "description", "debit", "credit", "currency", "functional_debit", "functional_credit"}}
+	for _, item := range e.Journals {
+		rows = append(rows, []string{
+			strconv.Itoa(e.ID), item.Date.Time.Format("2006-01-02"),
+			strconv.Itoa(item.Code), item.Account.Name, item.Description,
+			bookkeeping.FormatAmount(item.Left, item.Currency), bookkeeping.FormatAmount(item.Right, item.Currency),
+			item.Currency,
+			bookkeeping.FormatAmount(item.FuncLeft, bookkeeping.DefaultCurrency), bookkeeping.FormatAmount(item.FuncRight, bookkeeping.DefaultCurrency),
+		})
+	}
+	return rows
+}
+
+func printEntry(w io.Writer, e bookkeeping.Entry) {
+	fmt.Fprintf(w, "Entry %s: %s %s\n", entryLink(e.ID, e.ReversalOf, e.ReversedBy), e.Date.Time.Format("2006/01/02"), e.Memo)
+	fprintLFW(w, "code", 10)
+	fprintLFW(w, "name", 30)
+	fprintLFW(w, "description", 40)
+	fprintLFW(w, "debit", 20)
+	fprintLFW(w, "credit", 20)
+	fprintLFW(w, "currency", 10)
+	fprintLFW(w, "debit ("+bookkeeping.DefaultCurrency+")", 20)
+	fprintLFW(w, "credit ("+bookkeeping.DefaultCurrency+")", 20)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 170))
+
+	for _, item := range e.Journals {
+		fprintLFW(w, item.Code, 10)
+		fprintLFW(w, item.Account.Name, 30)
+		fprintLFW(w, item.Description, 40)
+		fprintLFW(w, bookkeeping.FormatAmount(item.Left, item.Currency), 20)
+		fprintLFW(w, bookkeeping.FormatAmount(item.Right, item.Currency), 20)
+		fprintLFW(w, item.Currency, 10)
+		fprintLFW(w, bookkeeping.FormatAmount(item.FuncLeft, bookkeeping.DefaultCurrency), 20)
+		fprintLFW(w, bookkeeping.FormatAmount(item.FuncRight, bookkeeping.DefaultCurrency), 20)
+		fmt.Fprintln(w)
+	}
+}
+
+// entryLink formats an entry ID with the link to its reversal or reversed entry.
+func entryLink(id, reversalOf, reversedBy int) string {
+	switch {
+	case reversalOf > 0:
+		return fmt.Sprintf("%d (rev of %d)", id, reversalOf)
+	case reversedBy > 0:
+		return fmt.Sprintf("%d (rev by %d)", id, reversedBy)
+	default:
+		return strconv.Itoa(id)
+	}
+}
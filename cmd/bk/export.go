@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func exportCmd() command {
+	fset := flag.NewFlagSet("bk export", flag.ExitOnError)
+	opts := &exportOpts{}
+	fset.StringVar(&opts.format, "format", "ledger", "Export file format. (ledger)")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk export [flags] > <file>")
+		fset.PrintDefaults()
+	}
+
+	return command{
+		name:        "export",
+		description: "Export accounts and journal entries to file",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return exportFile(opts, glOpts)
+		},
+	}
+}
+
+type exportOpts struct {
+	format string
+}
+
+func exportFile(opts *exportOpts, glOpts *globalOpts) error {
+	if opts.format != "ledger" {
+		return fmt.Errorf("unsupported export format: '%s'", opts.format)
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	accs, err := bk.FetchAc(bookkeeping.FetchAcOpts{})
+	if err != nil {
+		return err
+	}
+
+	names, err := bk.FetchAccountNames()
+	if err != nil {
+		return err
+	}
+
+	entries, err := bk.FetchEntries()
+	if err != nil {
+		return err
+	}
+
+	return writeLedger(glOpts.output, accs, names, entries)
+}
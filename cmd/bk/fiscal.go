@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func fiscalCmd() command {
+	fset := flag.NewFlagSet("bk fiscal", flag.ExitOnError)
+	opts := &fiscalOpts{}
+	fset.IntVar(&opts.startMonth, "start-month", 0, "Set the first month of fiscal years. (1-12)")
+
+	return command{
+		name:        "fiscal",
+		description: "Show or set fiscal year",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return fiscal(opts, glOpts)
+		},
+	}
+}
+
+type fiscalOpts struct {
+	startMonth int
+}
+
+func fiscal(opts *fiscalOpts, glOpts *globalOpts) error {
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	if opts.startMonth != 0 {
+		if err := bk.SetFiscalYearStartMonth(time.Month(opts.startMonth)); err != nil {
+			return err
+		}
+	}
+
+	m, err := bk.FiscalYearStartMonth()
+	if err != nil {
+		return err
+	}
+	closed, err := bk.ClosedThrough()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "fiscal year starts in: %s\n", m)
+	if closed.IsZero() {
+		fmt.Fprintln(glOpts.output, "closed through:        -")
+	} else {
+		fmt.Fprintf(glOpts.output, "closed through:        %s\n", closed.Format("2006/01/02"))
+	}
+	return nil
+}
@@ -5,6 +5,13 @@ import (
 	"time"
 )
 
+// today returns the current date at midnight in UTC, in the same way as dates parsed by dateFlag,
+// so that the default date of a command is the same calendar date as '-date' of today.
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
 type dateFlag struct {
 	date *time.Time
 }
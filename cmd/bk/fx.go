@@ -0,0 +1,301 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func fxCmd() command {
+	fset := flag.NewFlagSet("bk fx", flag.ExitOnError)
+
+	subcommands := []command{
+		fxSetCmd(),
+		fxListCmd(),
+		fxImportCmd(),
+		fxRevalueCmd(),
+	}
+
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Subcommands:")
+		for _, cmd := range subcommands {
+			if cmd.fset == nil || cmd.fn == nil {
+				continue // skip not implemented
+			}
+
+			fmt.Fprintf(fset.Output(), "  %s:%s%s\n", cmd.name, strings.Repeat(" ", 12-len(cmd.name)), cmd.description)
+		}
+	}
+
+	return command{
+		name:          "fx",
+		description:   "Manage exchange rates and revalue foreign currency balances",
+		hasSubcommand: true,
+		fset:          fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return subcmd("bk fx", subcommands, fset.Args(), glOpts)
+		},
+	}
+}
+
+func fxSetCmd() command {
+	fset := flag.NewFlagSet("bk fx set", flag.ExitOnError)
+	opts := &fxSetOpts{date: today()}
+	fset.StringVar(&opts.base, "base", "", "Base currency of the rate. e.g. USD")
+	fset.StringVar(&opts.quote, "quote", bookkeeping.DefaultCurrency, "Quote currency of the rate.")
+	fset.Var(&dateFlag{&opts.date}, "date", "Date of the rate. (format: yyyymmdd)")
+	fset.StringVar(&opts.rate, "rate", "", "Amount of quote currency per 1 base currency. e.g. 108.25")
+
+	return command{
+		name:        "set",
+		description: "Set exchange rate",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return fxSet(opts, glOpts)
+		},
+	}
+}
+
+type fxSetOpts struct {
+	base  string
+	quote string
+	date  time.Time
+	rate  string
+}
+
+func fxSet(opts *fxSetOpts, glOpts *globalOpts) error {
+	if opts.base == "" {
+		return fmt.Errorf("-base is required")
+	}
+	if opts.rate == "" {
+		return fmt.Errorf("-rate is required")
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	r := bookkeeping.ExchangeRate{
+		Base:  opts.base,
+		Quote: opts.quote,
+		Date:  sql.NullTime{Time: opts.date, Valid: true},
+		Rate:  opts.rate,
+	}
+	if err := bk.SetRates(r); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "rate %s/%s %s set on %s\n", r.Base, r.Quote, r.Rate, opts.date.Format("2006/01/02"))
+	return nil
+}
+
+func fxListCmd() command {
+	fset := flag.NewFlagSet("bk fx list", flag.ExitOnError)
+	opts := &fxListOpts{}
+	fset.StringVar(&opts.base, "base", "", "Base currency filter.")
+	fset.StringVar(&opts.quote, "quote", "", "Quote currency filter.")
+
+	return command{
+		name:        "list",
+		description: "List exchange rates",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return fxList(opts, glOpts)
+		},
+	}
+}
+
+type fxListOpts struct {
+	base  string
+	quote string
+}
+
+func fxList(opts *fxListOpts, glOpts *globalOpts) error {
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	rates, err := bk.FetchRates(bookkeeping.FetchRatesOpts{Base: opts.base, Quote: opts.quote})
+	if err != nil {
+		return err
+	}
+
+	return render(glOpts, report{
+		data:  rates,
+		text:  func(w io.Writer) { printRates(w, rates) },
+		table: func() [][]string { return ratesTable(rates) },
+	})
+}
+
+func ratesTable(rates []bookkeeping.ExchangeRate) [][]string {
+	rows := [][]string{{"date", "base", "quote", "rate"}}
+	for _, r := range rates {
+		rows = append(rows, []string{r.Date.Time.Format("2006-01-02"), r.Base, r.Quote, r.Rate})
+	}
+	return rows
+}
+
+func printRates(w io.Writer, rates []bookkeeping.ExchangeRate) {
+	fprintLFW(w, "date", 12)
+	fprintLFW(w, "pair", 10)
+	fprintRFW(w, "rate", 20)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 42))
+
+	for _, r := range rates {
+		fprintLFW(w, r.Date.Time.Format("2006/01/02"), 12)
+		fprintLFW(w, r.Base+"/"+r.Quote, 10)
+		fprintRFW(w, r.Rate, 20)
+		fmt.Fprintln(w)
+	}
+}
+
+func fxImportCmd() command {
+	fset := flag.NewFlagSet("bk fx import", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk fx import <file>")
+		fmt.Fprintln(fset.Output())
+		fmt.Fprintln(fset.Output(), "CSV columns: date,base,quote,rate")
+		fmt.Fprintln(fset.Output(), "  date: yyyymmdd, yyyy-mm-dd or yyyy/mm/dd")
+		fmt.Fprintln(fset.Output(), "  rate: amount of quote currency per 1 base currency")
+	}
+
+	return command{
+		name:        "import",
+		description: "Import exchange rates from CSV file",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			if fset.NArg() != 1 {
+				fset.Usage()
+				return fmt.Errorf("import file is required")
+			}
+			return fxImport(fset.Arg(0), glOpts)
+		},
+	}
+}
+
+func fxImport(file string, glOpts *globalOpts) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rates, err := parseCSVRates(f)
+	if err != nil {
+		return err
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	if err := bk.SetRates(rates...); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "%d rates imported\n", len(rates))
+	return nil
+}
+
+// parseCSVRates parses CSV rows of date,base,quote,rate.
+// The first row is skipped as a header if its first column is "date".
+func parseCSVRates(r io.Reader) ([]bookkeeping.ExchangeRate, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	rates := []bookkeeping.ExchangeRate{}
+	for row := 1; ; row++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i := range rec {
+			rec[i] = strings.TrimSpace(rec[i])
+		}
+
+		if row == 1 && len(rec) > 0 && rec[0] == "date" {
+			continue
+		}
+
+		if len(rec) != 4 {
+			return nil, fmt.Errorf("row %d: want 4 columns (date,base,quote,rate), but got %d", row, len(rec))
+		}
+
+		d, err := parseDate(rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		rates = append(rates, bookkeeping.ExchangeRate{
+			Base:  rec[1],
+			Quote: rec[2],
+			Date:  sql.NullTime{Time: d, Valid: true},
+			Rate:  rec[3],
+		})
+	}
+
+	return rates, nil
+}
+
+func fxRevalueCmd() command {
+	fset := flag.NewFlagSet("bk fx revalue", flag.ExitOnError)
+	opts := &fxRevalueOpts{date: today()}
+	fset.Var(&dateFlag{&opts.date}, "date", "Date of revaluation. (format: yyyymmdd)")
+
+	return command{
+		name:        "revalue",
+		description: "Post unrealized FX gain or loss of foreign currency balances",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return fxRevalue(opts, glOpts)
+		},
+	}
+}
+
+type fxRevalueOpts struct {
+	date time.Time
+}
+
+func fxRevalue(opts *fxRevalueOpts, glOpts *globalOpts) error {
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	id, err := bk.Revalue(opts.date)
+	if err != nil {
+		return err
+	}
+
+	if id == 0 {
+		fmt.Fprintln(glOpts.output, "nothing to revalue")
+		return nil
+	}
+	fmt.Fprintf(glOpts.output, "entry %d posted\n", id)
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_parseCSVRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    int
+		wantErr bool
+	}{
+		{"ok, with header",
+			"date,base,quote,rate\n" +
+				"2020-06-01,USD,JPY,108.25\n" +
+				"2020-06-02,USD,JPY,107.9\n",
+			2, false,
+		},
+		{"ok, without header",
+			"20200601,EUR,JPY,121\n",
+			1, false,
+		},
+		{"error, wrong date",
+			"2020-13-01,USD,JPY,108\n",
+			0, true,
+		},
+		{"error, missing columns",
+			"2020-06-01,USD,108\n",
+			0, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCSVRates(strings.NewReader(tt.csv))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCSVRates() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != tt.want {
+				t.Errorf("parseCSVRates() got %v rates, want %v", len(got), tt.want)
+			}
+		})
+	}
+}
@@ -4,10 +4,9 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/yoskeoka/bookkeeping"
 )
@@ -24,6 +23,13 @@ func glCmd() command {
 		opts.code = append(opts.code, code)
 		return nil
 	})
+	fset.Var(&dateFlag{&opts.startDate}, "start", "start date of general ledger time period. (format: yyyymmdd)")
+	fset.Var(&dateFlag{&opts.endDate}, "end", "end date of general ledger time period. (format: yyyymmdd)")
+	fset.StringVar(&opts.descFilter, "desc", "", "Description filter (wildcard '*' supported)")
+	fset.StringVar(&opts.minAmount, "min", "", "Minimum amount of debit or credit in the currency of the ledgers. e.g. 5000 or 12.50USD")
+	fset.StringVar(&opts.maxAmount, "max", "", "Maximum amount of debit or credit in the currency of the ledgers. e.g. 5000 or 12.50USD")
+	fset.BoolVar(&opts.includeEmpty, "all", false, "Include accounts without any journal in the period.")
+	fset.StringVar(&opts.currency, "currency", "", "Show only journals in the currency, with amounts in the currency. (default: all journals in functional currency)")
 
 	return command{
 		name:        "gl",
@@ -37,19 +43,48 @@ func glCmd() command {
 }
 
 type glOpts struct {
-	code []int
+	code       []int
+	startDate  time.Time
+	endDate    time.Time
+	descFilter string
+	minAmount  string
+	maxAmount  string
+
+	includeEmpty bool
+	currency     string
 }
 
 func gl(opts *glOpts, glOpts *globalOpts) error {
+	// amounts are compared in the currency of the ledgers
+	currency := opts.currency
+	if currency == "" {
+		currency = bookkeeping.DefaultCurrency
+	}
+	minAmount, err := parseLedgerAmount(opts.minAmount, currency)
+	if err != nil {
+		return err
+	}
+	maxAmount, err := parseLedgerAmount(opts.maxAmount, currency)
+	if err != nil {
+		return err
+	}
 
-	db, err := bookkeeping.NewDB(filepath.Join(glOpts.dataDir, databaseName))
+	db, err := openBook(glOpts)
 	if err != nil {
 		return err
 	}
 	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
 
 	fetchGLOpts := bookkeeping.FetchGLOpts{
 		AccountIDList: append(make([]int, 0, len(opts.code)), opts.code...),
+		Start:         opts.startDate,
+		End:           opts.endDate,
+		DescFilter:    opts.descFilter,
+		MinAmount:     minAmount,
+		MaxAmount:     maxAmount,
+		IncludeEmpty:  opts.includeEmpty,
+		Currency:      opts.currency,
 	}
 
 	items, err := bk.FetchGL(fetchGLOpts)
@@ -61,39 +96,83 @@ func gl(opts *glOpts, glOpts *globalOpts) error {
 		return fmt.Errorf("no journal records found")
 	}
 
-	printGL(glOpts.output, items)
+	return render(glOpts, report{
+		data:  items,
+		text:  func(w io.Writer) { printGL(w, glOpts.locale, items) },
+		table: func() [][]string { return glTable(items) },
+	})
+}
+
+func glTable(items []bookkeeping.Ledger) [][]string {
+	rows := [][]string{{"code", "name", "date", "entry", "description", "debit", "credit", "balance"}}
+	for _, ledger := range items {
+		code := strconv.Itoa(ledger.Account.Code)
+		rows = append(rows, []string{code, ledger.Account.Name, "", "", "opening balance", "", "", bookkeeping.FormatAmount(ledger.Opening, ledger.Currency)})
+		for _, item := range ledger.Lines {
+			rows = append(rows, []string{
+				code, ledger.Account.Name,
+				item.Date.Time.Format("2006-01-02"), strconv.Itoa(item.EntryID), item.Description,
+				bookkeeping.FormatAmount(item.Debit, ledger.Currency), bookkeeping.FormatAmount(item.Credit, ledger.Currency), bookkeeping.FormatAmount(item.Balance, ledger.Currency),
+			})
+		}
+	}
+	return rows
+}
+
+// parseLedgerAmount parses an amount in the currency, with an optional currency code.
+// Empty string is 0.
+func parseLedgerAmount(s, currency string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if s[len(s)-1] >= '0' && s[len(s)-1] <= '9' {
+		s += currency
+	}
 
-	return nil
+	m, err := bookkeeping.ParseMoney(s)
+	if err != nil {
+		return 0, err
+	}
+	if m.Currency != currency {
+		return 0, fmt.Errorf("amount must be in %s of the ledgers, but got %s", currency, m.Currency)
+	}
+	return m.Amount, nil
 }
 
-func printGL(w io.Writer, items map[int][]bookkeeping.Journal) {
-	fmt.Fprintln(w, "General Ledger:")
+func printGL(w io.Writer, lang string, items []bookkeeping.Ledger) {
+	fmt.Fprintln(w, label(lang, "General Ledger")+":")
 
-	for code, item := range items {
-		first := item[0]
+	for _, item := range items {
 		fmt.Fprintln(w)
-		printGLItem(w, code, first.Account.Name, item)
+		printGLItem(w, lang, item)
 	}
 }
 
-func printGLItem(w io.Writer, code int, name string, items []bookkeeping.Journal) {
+func printGLItem(w io.Writer, lang string, ledger bookkeeping.Ledger) {
 
-	fmt.Fprintf(w, "Account code %d: '%s'\n", code, name)
-	fprintLFW(w, "date", 20)
-	fprintLFW(w, "description", 40)
-	fprintLFW(w, "debit", 20)
-	fprintLFW(w, "credit", 20)
+	fmt.Fprintf(w, "%s %d: '%s' (%s)\n", label(lang, "Account code"), ledger.Account.Code, ledger.Account.Name, ledger.Currency)
+	fprintLFW(w, label(lang, "date"), 20)
+	fprintLFW(w, label(lang, "entry"), 20)
+	fprintLFW(w, label(lang, "description"), 40)
+	fprintLFW(w, label(lang, "debit"), 20)
+	fprintLFW(w, label(lang, "credit"), 20)
+	fprintLFW(w, label(lang, "balance"), 20)
 	fmt.Fprintln(w)
-	fmt.Fprintln(w, strings.Repeat("-", 100))
+	fmt.Fprintln(w, strings.Repeat("-", 140))
 
-	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
-	sort.SliceStable(items, func(i, j int) bool { return items[i].Date.Time.Before(items[j].Date.Time) })
+	fprintLFW(w, "", 40)
+	fprintLFW(w, label(lang, "opening balance"), 80)
+	fprintLFW(w, bookkeeping.FormatAmount(ledger.Opening, ledger.Currency), 20)
+	fmt.Fprintln(w)
 
-	for _, item := range items {
+	for _, item := range ledger.Lines {
 		fprintLFW(w, item.Date.Time.Format("2006/01/02"), 20)
+		fprintLFW(w, entryLink(item.EntryID, item.ReversalOf, item.ReversedBy), 20)
 		fprintLFW(w, item.Description, 40)
-		fprintLFW(w, strconv.Itoa(item.Left), 20)
-		fprintLFW(w, strconv.Itoa(item.Right), 20)
+		fprintLFW(w, bookkeeping.FormatAmount(item.Debit, ledger.Currency), 20)
+		fprintLFW(w, bookkeeping.FormatAmount(item.Credit, ledger.Currency), 20)
+		fprintLFW(w, bookkeeping.FormatAmount(item.Balance, ledger.Currency), 20)
 		fmt.Fprintln(w)
 	}
 }
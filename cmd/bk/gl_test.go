@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func Test_parseLedgerAmount(t *testing.T) {
+	tests := []struct {
+		s        string
+		currency string
+		want     int
+		wantErr  bool
+	}{
+		{"", "JPY", 0, false},
+		{"5000", "JPY", 5000, false},
+		{"12.50", "USD", 1250, false},
+		{"12.50USD", "USD", 1250, false},
+		{"12.50USD", "JPY", 0, true},
+		{"-500", "JPY", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseLedgerAmount(tt.s, tt.currency)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLedgerAmount(%s, %s) error = %v, wantErr %v", tt.s, tt.currency, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseLedgerAmount(%s, %s) = %v, want %v", tt.s, tt.currency, got, tt.want)
+		}
+	}
+}
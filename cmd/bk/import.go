@@ -0,0 +1,217 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func importCmd() command {
+	fset := flag.NewFlagSet("bk import", flag.ExitOnError)
+	opts := &importOpts{}
+	fset.StringVar(&opts.format, "format", "csv", "Import file format. (csv or ledger)")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk import [flags] <file>")
+		fset.PrintDefaults()
+		fmt.Fprintln(fset.Output())
+		fmt.Fprintln(fset.Output(), "CSV columns: entry,date,side,code,amount,description")
+		fmt.Fprintln(fset.Output(), "  entry: key to group rows into an entry")
+		fmt.Fprintln(fset.Output(), "  date:  yyyymmdd, yyyy-mm-dd or yyyy/mm/dd")
+		fmt.Fprintln(fset.Output(), "  side:  debit or credit")
+		fmt.Fprintln(fset.Output())
+		fmt.Fprintln(fset.Output(), "Ledger: plain-text journal written by bk export -format ledger")
+		fmt.Fprintln(fset.Output(), "  <date> [(<entry ID>)] <memo>")
+		fmt.Fprintln(fset.Output(), "      <code or name>  <amount>[ <currency>]  [; <description>]")
+		fmt.Fprintln(fset.Output(), "  positive amounts are debits, negative amounts are credits, and one amount of an entry may be omitted")
+	}
+
+	return command{
+		name:        "import",
+		description: "Import journal entries from file",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			if fset.NArg() != 1 {
+				fset.Usage()
+				return fmt.Errorf("import file is required")
+			}
+			opts.file = fset.Arg(0)
+			return importFile(opts, glOpts)
+		},
+	}
+}
+
+type importOpts struct {
+	format string
+	file   string
+}
+
+func importFile(opts *importOpts, glOpts *globalOpts) error {
+	if opts.format != "csv" && opts.format != "ledger" {
+		return fmt.Errorf("unsupported import format: '%s'", opts.format)
+	}
+
+	f, err := os.Open(opts.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if opts.format == "ledger" {
+		return importLedger(f, glOpts)
+	}
+
+	items, err := parseCSVEntries(f)
+	if err != nil {
+		return err
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	entries := make([]bookkeeping.Entry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, item.entry)
+	}
+
+	ids, err := bk.PostEntries(entries)
+	if err != nil {
+		var entryErr *bookkeeping.EntryError
+		if errors.As(err, &entryErr) {
+			item := items[entryErr.Index]
+			return fmt.Errorf("entry '%s' (rows %s): %w", item.key, joinInts(item.rows), entryErr.Err)
+		}
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "%d entries imported\n", len(ids))
+	return nil
+}
+
+// csvEntry is an entry parsed from CSV rows with the same entry key.
+type csvEntry struct {
+	key string
+	// rows are the line numbers of the rows in the file.
+	rows  []int
+	entry bookkeeping.Entry
+}
+
+// parseCSVEntries parses CSV rows into entries grouped by the entry key, in order of first appearance.
+// The first row is skipped as a header if its first column is "entry".
+func parseCSVEntries(r io.Reader) ([]csvEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	entries := []csvEntry{}
+	idx := make(map[string]int)
+
+	for n := 1; ; n++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if n == 1 && len(rec) > 0 && strings.TrimSpace(rec[0]) == "entry" {
+			continue
+		}
+		// blank lines and quoted fields with line breaks make records differ from lines
+		row, _ := cr.FieldPos(0)
+
+		key, j, err := parseCSVJournal(rec)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		i, ok := idx[key]
+		if !ok {
+			i = len(entries)
+			idx[key] = i
+			entries = append(entries, csvEntry{key: key})
+		}
+		entries[i].rows = append(entries[i].rows, row)
+		entries[i].entry.Journals = append(entries[i].entry.Journals, j)
+	}
+
+	return entries, nil
+}
+
+func parseCSVJournal(rec []string) (key string, j bookkeeping.Journal, err error) {
+	if len(rec) < 5 || len(rec) > 6 {
+		return "", j, fmt.Errorf("want 5 or 6 columns (entry,date,side,code,amount[,description]), but got %d", len(rec))
+	}
+	for i := range rec {
+		rec[i] = strings.TrimSpace(rec[i])
+	}
+
+	key = rec[0]
+	if key == "" {
+		return "", j, fmt.Errorf("entry key is empty")
+	}
+
+	d, err := parseDate(rec[1])
+	if err != nil {
+		return "", j, err
+	}
+	j.Date = sql.NullTime{Time: d, Valid: true}
+
+	code, err := strconv.Atoi(rec[3])
+	if err != nil {
+		return "", j, fmt.Errorf("cannot parse '%s' as account code: %w", rec[3], err)
+	}
+	j.Code = code
+
+	amount, err := bookkeeping.ParseMoney(rec[4])
+	if err != nil {
+		return "", j, err
+	}
+	j.Currency = amount.Currency
+
+	switch rec[2] {
+	case "debit":
+		j.Left = amount.Amount
+	case "credit":
+		j.Right = amount.Amount
+	default:
+		return "", j, fmt.Errorf("side must be 'debit' or 'credit', but got '%s'", rec[2])
+	}
+
+	if len(rec) == 6 {
+		j.Description = rec[5]
+	}
+
+	return key, j, nil
+}
+
+var dateFormats = []string{"20060102", "2006-01-02", "2006/01/02"}
+
+func parseDate(s string) (time.Time, error) {
+	for _, f := range dateFormats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse '%s' as date, supported formats are 'yyyymmdd', 'yyyy-mm-dd' and 'yyyy/mm/dd'", s)
+}
+
+func joinInts(v []int) string {
+	s := make([]string, 0, len(v))
+	for _, i := range v {
+		s = append(s, strconv.Itoa(i))
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_parseCSVEntries(t *testing.T) {
+	tests := []struct {
+		name     string
+		csv      string
+		wantKeys []string
+		wantRows [][]int
+		wantErr  bool
+	}{
+		{"ok, with header",
+			"entry,date,side,code,amount,description\n" +
+				"a,2020-06-01,debit,7300,1000,文具\n" +
+				"a,2020-06-01,credit,1110,1000,文具\n",
+			[]string{"a"}, [][]int{{2, 3}}, false,
+		},
+		{"ok, without header and description, interleaved",
+			"1,20200601,debit,7300,1000\n" +
+				"2,20200602,debit,7300,500\n" +
+				"1,20200601,credit,1110,1000\n" +
+				"2,20200602,credit,1110,500\n",
+			[]string{"1", "2"}, [][]int{{1, 3}, {2, 4}}, false,
+		},
+		{"ok, line numbers with blank lines and multi-line descriptions",
+			"entry,date,side,code,amount,description\n" +
+				"\n" +
+				"a,2020-06-01,debit,7300,1000,\"文具\n事務用品\"\n" +
+				"a,2020-06-01,credit,1110,1000,文具\n",
+			[]string{"a"}, [][]int{{3, 5}}, false,
+		},
+		{"error, wrong side",
+			"a,2020-06-01,left,7300,1000\n",
+			nil, nil, true,
+		},
+		{"error, wrong amount",
+			"a,2020-06-01,debit,7300,1000yen\n",
+			nil, nil, true,
+		},
+		{"error, wrong date",
+			"a,2020-13-01,debit,7300,1000\n",
+			nil, nil, true,
+		},
+		{"error, missing columns",
+			"a,2020-06-01,debit,7300\n",
+			nil, nil, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCSVEntries(strings.NewReader(tt.csv))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCSVEntries() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.wantKeys) {
+				t.Fatalf("parseCSVEntries() got %v entries, want %v", len(got), len(tt.wantKeys))
+			}
+			for i, e := range got {
+				if e.key != tt.wantKeys[i] {
+					t.Errorf("parseCSVEntries() entry %d key = %v, want %v", i, e.key, tt.wantKeys[i])
+				}
+				if joinInts(e.rows) != joinInts(tt.wantRows[i]) {
+					t.Errorf("parseCSVEntries() entry %d rows = %v, want %v", i, e.rows, tt.wantRows[i])
+				}
+				if len(e.entry.Journals) != len(tt.wantRows[i]) {
+					t.Errorf("parseCSVEntries() entry %d has %v journals, want %v", i, len(e.entry.Journals), len(tt.wantRows[i]))
+				}
+			}
+		})
+	}
+}
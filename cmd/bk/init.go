@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func initCmd() command {
+	fset := flag.NewFlagSet("bk init", flag.ExitOnError)
+	opts := &initOpts{}
+	fset.StringVar(&opts.template, "template", bookkeeping.DefaultAccountTemplate, "Chart of accounts template of the new book.")
+	fset.BoolVar(&opts.list, "list", false, "List chart of accounts templates.")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: bk [-book <name>] init [flags]")
+		fset.PrintDefaults()
+		fmt.Fprintln(fset.Output())
+		fmt.Fprintln(fset.Output(), "Templates:")
+		printTemplates(fset.Output(), bookkeeping.AccountTemplates)
+	}
+
+	return command{
+		name:        "init",
+		description: "Create the book with a chart of accounts template",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			if opts.list {
+				return templateList(glOpts)
+			}
+			return bookCreate(glOpts.book, opts.template, glOpts)
+		},
+	}
+}
+
+type initOpts struct {
+	template string
+	list     bool
+}
+
+func templateList(glOpts *globalOpts) error {
+	templates := bookkeeping.AccountTemplates
+	return render(glOpts, report{
+		data: templates,
+		text: func(w io.Writer) { printTemplates(w, templates) },
+		table: func() [][]string {
+			rows := [][]string{{"name", "lang", "description"}}
+			for _, t := range templates {
+				rows = append(rows, []string{t.Name, t.Lang, t.Description})
+			}
+			return rows
+		},
+	})
+}
+
+func printTemplates(w io.Writer, templates []bookkeeping.AccountTemplate) {
+	for _, t := range templates {
+		fmt.Fprintf(w, "  %s:%s%s (%s)\n", t.Name, strings.Repeat(" ", 12-len(t.Name)), t.Description, t.Lang)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+// labels are the labels of text output by language, keyed by the English label.
+var labels = map[string]map[string]string{
+	"ja": {
+		// accounts
+		"Accounts List": "勘定科目一覧",
+		"code":          "コード",
+		"name":          "名称",
+		"bs/pl":         "BS/PL",
+		"debit/credit":  "借方/貸方",
+		"status":        "状態",
+		"debit":         "借方",
+		"credit":        "貸方",
+		"active":        "有効",
+		"inactive":      "無効",
+
+		// general ledger
+		"General Ledger":  "総勘定元帳",
+		"Account code":    "勘定科目コード",
+		"date":            "日付",
+		"entry":           "仕訳",
+		"description":     "摘要",
+		"balance":         "残高",
+		"opening balance": "前期繰越",
+
+		// profit and loss statement
+		"Profit and Loss Statement":                "損益計算書",
+		"amount":                                   "金額",
+		"Net Sales":                                "売上高",
+		"Cost Sales":                               "売上原価",
+		"Gross Profit":                             "売上総利益",
+		"Operating Expences":                       "販売費及び一般管理費",
+		"Operating Income":                         "営業利益",
+		"Non Operating Incomes":                    "営業外収益",
+		"Non Operating Expences":                   "営業外費用",
+		"Extraordinary Incomes":                    "特別利益",
+		"Extraordinary Expences":                   "特別損失",
+		"Income Before Provision For Income Taxes": "税引前当期純利益",
+		"Provision For Income Taxes":               "法人税等",
+		"Net Income":                               "当期純利益",
+
+		// balance sheet
+		"Balance Sheet":                "貸借対照表",
+		"Assets":                       "資産",
+		"Liabilities":                  "負債",
+		"Equity":                       "純資産",
+		"Total Current Assets":         "流動資産合計",
+		"Total Noncurrent Assets":      "固定資産合計",
+		"Total Assets":                 "資産合計",
+		"Total Current Liabilities":    "流動負債合計",
+		"Total Noncurrent Liabilities": "固定負債合計",
+		"Total Liabilities":            "負債合計",
+		"Owner's Capital":              "資本金",
+		"Capital Surplus":              "資本剰余金",
+		"Retained Earnings":            "利益剰余金",
+		"Total Equity":                 "純資産合計",
+		"Total Liabilities and Equity": "負債純資産合計",
+		"Total":                        "合計",
+		"Total %s":                     "%s合計",
+
+		// trial balance
+		"Trial Balance":      "試算表",
+		"opening":            "期首残高",
+		"closing":            "期末残高",
+		"Opening Balance":    "期首残高",
+		"Closing Balance":    "期末残高",
+		"IMBALANCE DETECTED": "貸借不一致",
+	},
+}
+
+// label returns the label of text output in the language, or the English label if not localized.
+func label(lang string, s string) string {
+	if l, ok := labels[lang][s]; ok {
+		return l
+	}
+	return s
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func Test_label(t *testing.T) {
+	tests := []struct {
+		lang string
+		s    string
+		want string
+	}{
+		{"", "Net Income", "Net Income"},
+		{"en", "Net Income", "Net Income"},
+		{"ja", "Net Income", "当期純利益"},
+		{"ja", "not localized", "not localized"},
+	}
+	for _, tt := range tests {
+		if got := label(tt.lang, tt.s); got != tt.want {
+			t.Errorf("label(%q, %q) = %q, want %q", tt.lang, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,624 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/yoskeoka/bookkeeping"
+)
+
+// The plain-text ledger format is a journal file in the style of hledger and beancount:
+//
+//	; comment
+//	account 1110 現金及び預金  ; bs, debit
+//	    ; name-en: Cash and Deposits
+//	account 7399 旧経費  ; pl, debit, inactive
+//
+//	2024/01/05 (1) 会社設立
+//	    1110 現金及び預金  500000  ; 会社設立
+//	    3100 資本金
+//
+//	2024/03/31 (2) 外貨預金
+//	    1111 外貨預金  1000.00 USD @@ 150000 JPY
+//	    3100 資本金  -150000
+//
+// An entry starts with a header of the date, the optional entry ID in parentheses and the memo,
+// followed by indented postings. A posting is an account code or name, and an amount
+// separated by two or more spaces or a tab, with the optional description after ';'.
+// Positive amounts are debits and negative amounts are credits. The amount of one posting of an entry
+// may be omitted, which is inferred to balance the entry. '@@' gives the amount in the functional currency.
+// Indented comment lines of an entry are tags, '; closing' for closing entries and
+// '; reversal of: <entry ID>' for reversing entries. Indented comment lines of an account directive are
+// tags of the localized names, '; name-<lang>: <name>'.
+// ';' and '\' in account names are escaped as '\;' and '\\', since ';' starts a comment.
+
+// ledgerAmountPattern matches the amount at the end of a posting, after two or more spaces or a tab,
+// so that account names may contain spaces.
+var ledgerAmountPattern = regexp.MustCompile(`(?:\t|  )\s*(-?[0-9.]+(?: ?[A-Z]{3})?(?:\s*@@\s*[0-9.]+(?: ?[A-Z]{3})?)?)$`)
+
+// ledgerAccount is an account directive of a ledger file.
+type ledgerAccount struct {
+	bookkeeping.Account
+	// typed is true if the directive has BS/PL and debit/credit, which are required to add the account.
+	typed bool
+	// names are the localized names by language.
+	names map[string]string
+}
+
+// ledgerEntry is an entry parsed from a ledger file.
+type ledgerEntry struct {
+	// line is the line number of the entry header.
+	line int
+	// id is the entry ID in the header, or 0.
+	id int
+	// reversalOf is the entry ID of the reversal of tag, or 0.
+	reversalOf int
+	entry      bookkeeping.Entry
+}
+
+type ledgerFile struct {
+	accounts []ledgerAccount
+	entries  []ledgerEntry
+}
+
+// ledgerPosting is a posting of which the amount may be omitted.
+type ledgerPosting struct {
+	line      int
+	journal   bookkeeping.Journal
+	hasAmount bool
+}
+
+// parseLedger parses a ledger file. Account names in postings are resolved by the account directives
+// in the file and by the accounts.
+func parseLedger(r io.Reader, accs []bookkeeping.Account) (ledgerFile, error) {
+	lf := ledgerFile{}
+
+	names := make(map[string]int)
+	for _, a := range accs {
+		names[a.Name] = a.Code
+	}
+
+	var cur *ledgerEntry
+	var postings []ledgerPosting
+	// acc is the account directive of which the tags follow, or nil.
+	var acc *ledgerAccount
+	finish := func() error {
+		if cur == nil {
+			return nil
+		}
+		jn, err := inferLedgerAmount(postings)
+		if err != nil {
+			return fmt.Errorf("entry at line %d: %w", cur.line, err)
+		}
+		cur.entry.Journals = jn
+		lf.entries = append(lf.entries, *cur)
+		cur, postings = nil, nil
+		return nil
+	}
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			acc = nil
+			if err := finish(); err != nil {
+				return lf, err
+			}
+
+		case (line[0] == ' ' || line[0] == '\t') && acc != nil && strings.HasPrefix(trimmed, ";"):
+			if err := parseLedgerAccountTag(acc, strings.TrimSpace(trimmed[1:])); err != nil {
+				return lf, fmt.Errorf("line %d: %w", n, err)
+			}
+
+		case line[0] == ' ' || line[0] == '\t':
+			if cur == nil {
+				return lf, fmt.Errorf("line %d: posting without entry header", n)
+			}
+			if strings.HasPrefix(trimmed, ";") {
+				if err := parseLedgerTag(cur, strings.TrimSpace(trimmed[1:])); err != nil {
+					return lf, fmt.Errorf("line %d: %w", n, err)
+				}
+				continue
+			}
+			p, err := parseLedgerPosting(trimmed, names)
+			if err != nil {
+				return lf, fmt.Errorf("line %d: %w", n, err)
+			}
+			p.line = n
+			postings = append(postings, p)
+
+		case line[0] == ';' || line[0] == '#':
+			// comment
+
+		case strings.HasPrefix(line, "account "):
+			if err := finish(); err != nil {
+				return lf, err
+			}
+			a, err := parseLedgerAccount(strings.TrimPrefix(line, "account "))
+			if err != nil {
+				return lf, fmt.Errorf("line %d: %w", n, err)
+			}
+			names[a.Name] = a.Code
+			lf.accounts = append(lf.accounts, a)
+			acc = &lf.accounts[len(lf.accounts)-1]
+
+		default:
+			if err := finish(); err != nil {
+				return lf, err
+			}
+			acc = nil
+			e, err := parseLedgerHeader(line)
+			if err != nil {
+				return lf, fmt.Errorf("line %d: %w", n, err)
+			}
+			e.line = n
+			cur = &e
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return lf, err
+	}
+
+	if err := finish(); err != nil {
+		return lf, err
+	}
+	return lf, nil
+}
+
+// splitLedgerComment splits a line into the body and the comment after the first ';' not escaped.
+// Escaped '\;' and '\\' in the body are unescaped.
+func splitLedgerComment(s string) (string, string) {
+	var body strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == ';' || s[i+1] == '\\'):
+			i++
+			body.WriteByte(s[i])
+		case s[i] == ';':
+			return strings.TrimSpace(body.String()), strings.TrimPrefix(s[i+1:], " ")
+		default:
+			body.WriteByte(s[i])
+		}
+	}
+	return strings.TrimSpace(body.String()), ""
+}
+
+// escapeLedgerName escapes '\' and ';' in an account name, which are unescaped by splitLedgerComment.
+func escapeLedgerName(s string) string {
+	return strings.NewReplacer("\\", "\\\\", ";", "\\;").Replace(s)
+}
+
+func parseLedgerAccount(s string) (ledgerAccount, error) {
+	body, comment := splitLedgerComment(s)
+
+	cols := strings.SplitN(body, " ", 2)
+	if len(cols) != 2 || strings.TrimSpace(cols[1]) == "" {
+		return ledgerAccount{}, fmt.Errorf("cannot parse '%s' as account directive, format: account <code> <name>", s)
+	}
+	code, err := strconv.Atoi(cols[0])
+	if err != nil {
+		return ledgerAccount{}, fmt.Errorf("cannot parse '%s' as account code: %w", cols[0], err)
+	}
+
+	a := ledgerAccount{Account: bookkeeping.Account{Code: code, Name: strings.TrimSpace(cols[1])}}
+	bspl, side := false, false
+	for _, tag := range strings.Split(comment, ",") {
+		switch strings.TrimSpace(tag) {
+		case "bs":
+			a.IsBS, bspl = true, true
+		case "pl":
+			a.IsBS, bspl = false, true
+		case "debit":
+			a.IsLeft, side = true, true
+		case "credit":
+			a.IsLeft, side = false, true
+		case "inactive":
+			a.Inactive = true
+		}
+	}
+	a.typed = bspl && side
+
+	return a, nil
+}
+
+func parseLedgerAccountTag(a *ledgerAccount, tag string) error {
+	if !strings.HasPrefix(tag, "name-") {
+		return nil
+	}
+	i := strings.Index(tag, ":")
+	if i < 0 {
+		return fmt.Errorf("cannot parse '%s' as localized name, format: name-<lang>: <name>", tag)
+	}
+	lang, name := strings.TrimPrefix(tag[:i], "name-"), strings.TrimSpace(tag[i+1:])
+	if lang == "" || name == "" {
+		return fmt.Errorf("cannot parse '%s' as localized name, format: name-<lang>: <name>", tag)
+	}
+
+	if a.names == nil {
+		a.names = make(map[string]string)
+	}
+	a.names[lang] = name
+	return nil
+}
+
+func parseLedgerHeader(s string) (ledgerEntry, error) {
+	le := ledgerEntry{}
+
+	ds, rest := s, ""
+	if i := strings.Index(s, " "); i >= 0 {
+		ds, rest = s[:i], s[i+1:]
+	}
+	d, err := parseDate(ds)
+	if err != nil {
+		return le, err
+	}
+	le.entry.Date = sql.NullTime{Time: d, Valid: true}
+
+	if strings.HasPrefix(rest, "(") {
+		i := strings.Index(rest, ")")
+		if i < 0 {
+			return le, fmt.Errorf("entry ID in '%s' is not closed with ')'", s)
+		}
+		id, err := strconv.Atoi(rest[1:i])
+		if err != nil {
+			return le, fmt.Errorf("cannot parse '%s' as entry ID: %w", rest[1:i], err)
+		}
+		le.id = id
+		rest = strings.TrimPrefix(rest[i+1:], " ")
+	}
+	le.entry.Memo = rest
+
+	return le, nil
+}
+
+func parseLedgerTag(le *ledgerEntry, tag string) error {
+	switch {
+	case tag == "closing":
+		le.entry.Closing = true
+	case strings.HasPrefix(tag, "reversal of:"):
+		v := strings.TrimSpace(strings.TrimPrefix(tag, "reversal of:"))
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("cannot parse '%s' as entry ID: %w", v, err)
+		}
+		le.reversalOf = id
+	}
+	return nil
+}
+
+func parseLedgerPosting(s string, names map[string]int) (ledgerPosting, error) {
+	p := ledgerPosting{}
+
+	body, desc := splitLedgerComment(s)
+	p.journal.Description = desc
+
+	account, amount := body, ""
+	if m := ledgerAmountPattern.FindStringSubmatchIndex(body); m != nil {
+		account, amount = strings.TrimSpace(body[:m[0]]), body[m[2]:m[3]]
+	}
+
+	code, err := resolveLedgerAccount(account, names)
+	if err != nil {
+		return p, err
+	}
+	p.journal.Code = code
+
+	if amount == "" {
+		return p, nil
+	}
+	p.hasAmount = true
+
+	funcAmount := ""
+	if i := strings.Index(amount, "@@"); i >= 0 {
+		amount, funcAmount = strings.TrimSpace(amount[:i]), amount[i+2:]
+	}
+
+	neg := strings.HasPrefix(amount, "-")
+	m, err := bookkeeping.ParseMoney(strings.ReplaceAll(strings.TrimPrefix(amount, "-"), " ", ""))
+	if err != nil {
+		return p, err
+	}
+	p.journal.Currency = m.Currency
+
+	fm := bookkeeping.Money{}
+	if funcAmount != "" {
+		fm, err = bookkeeping.ParseMoney(strings.ReplaceAll(funcAmount, " ", ""))
+		if err != nil {
+			return p, err
+		}
+		if fm.Currency != bookkeeping.DefaultCurrency {
+			return p, fmt.Errorf("amount after '@@' must be in functional currency %s, but got %s", bookkeeping.DefaultCurrency, fm.Currency)
+		}
+	}
+
+	if neg {
+		p.journal.Right, p.journal.FuncRight = m.Amount, fm.Amount
+	} else {
+		p.journal.Left, p.journal.FuncLeft = m.Amount, fm.Amount
+	}
+	return p, nil
+}
+
+// resolveLedgerAccount returns the account code of the account name, or of the code with an optional name.
+func resolveLedgerAccount(s string, names map[string]int) (int, error) {
+	if code, ok := names[s]; ok {
+		return code, nil
+	}
+
+	c := s
+	if i := strings.Index(s, " "); i >= 0 {
+		c = s[:i]
+	}
+	code, err := strconv.Atoi(c)
+	if err != nil {
+		return 0, fmt.Errorf("account '%s' is not found", s)
+	}
+	return code, nil
+}
+
+// inferLedgerAmount returns the journals of the postings, inferring the amount of the posting without amount
+// so that the entry balances.
+func inferLedgerAmount(postings []ledgerPosting) ([]bookkeeping.Journal, error) {
+	jn := make([]bookkeeping.Journal, 0, len(postings))
+	missing := -1
+	currencies := make(map[string]bool)
+	currency, sum := "", 0
+	for i, p := range postings {
+		if !p.hasAmount {
+			if missing >= 0 {
+				return nil, fmt.Errorf("amounts of line %d and %d are both omitted", postings[missing].line, p.line)
+			}
+			missing = i
+		} else {
+			currency = p.journal.Currency
+			currencies[currency] = true
+			sum += p.journal.Left - p.journal.Right
+		}
+		jn = append(jn, p.journal)
+	}
+
+	if missing < 0 {
+		return jn, nil
+	}
+	if len(currencies) != 1 {
+		return nil, fmt.Errorf("amount of line %d cannot be inferred from postings in %d currencies", postings[missing].line, len(currencies))
+	}
+
+	jn[missing].Currency = currency
+	switch {
+	case sum > 0:
+		jn[missing].Right = sum
+	case sum < 0:
+		jn[missing].Left = -sum
+	default:
+		return nil, fmt.Errorf("amount of line %d is inferred to be zero", postings[missing].line)
+	}
+	return jn, nil
+}
+
+// ledgerAccountWidth is the width of the account column of postings.
+const ledgerAccountWidth = 36
+
+// writeLedger writes the accounts with their localized names and the entries in the plain-text ledger format.
+// names are the localized names of accounts by account code and language.
+func writeLedger(w io.Writer, accs []bookkeeping.Account, names map[int]map[string]string, entries []bookkeeping.Entry) error {
+	bw := bufio.NewWriter(w)
+
+	for _, a := range accs {
+		tags := []string{"pl", sideName(a.IsLeft)}
+		if a.IsBS {
+			tags[0] = "bs"
+		}
+		if a.Inactive {
+			tags = append(tags, "inactive")
+		}
+		fmt.Fprintf(bw, "account %d %s  ; %s\n", a.Code, escapeLedgerName(a.Name), strings.Join(tags, ", "))
+
+		langs := make([]string, 0, len(names[a.Code]))
+		for lang := range names[a.Code] {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			fmt.Fprintf(bw, "    ; name-%s: %s\n", lang, names[a.Code][lang])
+		}
+	}
+
+	for _, e := range entries {
+		fmt.Fprintln(bw)
+		fmt.Fprintf(bw, "%s (%d)", e.Date.Time.Format("2006/01/02"), e.ID)
+		if e.Memo != "" {
+			fmt.Fprint(bw, " "+e.Memo)
+		}
+		fmt.Fprintln(bw)
+
+		if e.Closing {
+			fmt.Fprintln(bw, "    ; closing")
+		}
+		if e.ReversalOf > 0 {
+			fmt.Fprintf(bw, "    ; reversal of: %d\n", e.ReversalOf)
+		}
+
+		for _, j := range e.Journals {
+			account := fmt.Sprintf("%d %s", j.Code, escapeLedgerName(j.Account.Name))
+			fmt.Fprint(bw, "    "+runewidth.FillRight(account, ledgerAccountWidth)+"  "+formatLedgerAmount(j))
+			if j.Description != "" {
+				fmt.Fprint(bw, "  ; "+j.Description)
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// formatLedgerAmount formats the amount of the journal, negative for credit, with the functional currency amount
+// after '@@' if the journal is in a foreign currency.
+func formatLedgerAmount(j bookkeeping.Journal) string {
+	c := j.Currency
+	if c == "" {
+		c = bookkeeping.DefaultCurrency
+	}
+
+	amount, funcAmount, sign := j.Left, j.FuncLeft, ""
+	if j.Right > 0 {
+		amount, funcAmount, sign = j.Right, j.FuncRight, "-"
+	}
+
+	if c == bookkeeping.DefaultCurrency {
+		return sign + bookkeeping.FormatAmount(amount, c)
+	}
+	return fmt.Sprintf("%s%s %s @@ %s %s", sign, bookkeeping.FormatAmount(amount, c), c,
+		bookkeeping.FormatAmount(funcAmount, bookkeeping.DefaultCurrency), bookkeeping.DefaultCurrency)
+}
+
+func sideName(isLeft bool) string {
+	if isLeft {
+		return "debit"
+	}
+	return "credit"
+}
+
+// importLedger adds and renames the accounts of the ledger file, posts its entries, then deactivates
+// the accounts marked inactive. Entries are validated before any of them is posted.
+func importLedger(r io.Reader, glOpts *globalOpts) error {
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	accs, err := bk.FetchAc(bookkeeping.FetchAcOpts{})
+	if err != nil {
+		return err
+	}
+	existing := make(map[int]bookkeeping.Account, len(accs))
+	for _, a := range accs {
+		existing[a.Code] = a
+	}
+
+	lf, err := parseLedger(r, accs)
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[int]bool)
+	for _, le := range lf.entries {
+		if le.reversalOf > 0 && !ids[le.reversalOf] {
+			return fmt.Errorf("entry at line %d: reversed entry (%d) must be before the entry in the file", le.line, le.reversalOf)
+		}
+		if le.id > 0 {
+			if ids[le.id] {
+				return fmt.Errorf("entry at line %d: entry ID (%d) is duplicated", le.line, le.id)
+			}
+			ids[le.id] = true
+		}
+	}
+
+	for _, la := range lf.accounts {
+		a, ok := existing[la.Code]
+		switch {
+		case !ok && !la.typed:
+			return fmt.Errorf("account %d: bs or pl and debit or credit are required to add the account", la.Code)
+		case !ok:
+			n := la.Account
+			n.Inactive = false
+			if err := bk.AddAccount(n); err != nil {
+				return err
+			}
+		case a.Name != la.Name:
+			if err := bk.RenameAccount(la.Code, la.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// names in the language of account names of the book are set by the account directives
+	accountsLang, err := bk.AccountsLang()
+	if err != nil {
+		return err
+	}
+	for _, la := range lf.accounts {
+		langs := make([]string, 0, len(la.names))
+		for lang := range la.names {
+			if lang != accountsLang {
+				langs = append(langs, lang)
+			}
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			if err := bk.SetAccountName(la.Code, lang, la.names[lang]); err != nil {
+				return fmt.Errorf("account %d: %w", la.Code, err)
+			}
+		}
+	}
+
+	for _, le := range lf.entries {
+		if err := bk.CheckEntry(le.entry); err != nil {
+			return fmt.Errorf("entry at line %d: %w", le.line, err)
+		}
+	}
+
+	// entries are posted in batches, so that a reversing entry refers to the ID of the posted reversed entry
+	posted := make(map[int]int)
+	batch := []ledgerEntry{}
+	flush := func() error {
+		entries := make([]bookkeeping.Entry, 0, len(batch))
+		for _, le := range batch {
+			entries = append(entries, le.entry)
+		}
+		newIDs, err := bk.PostEntries(entries)
+		if err != nil {
+			var entryErr *bookkeeping.EntryError
+			if errors.As(err, &entryErr) {
+				return fmt.Errorf("entry at line %d: %w", batch[entryErr.Index].line, entryErr.Err)
+			}
+			return err
+		}
+		for i, le := range batch {
+			if le.id > 0 {
+				posted[le.id] = newIDs[i]
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for _, le := range lf.entries {
+		if le.reversalOf > 0 {
+			if _, ok := posted[le.reversalOf]; !ok {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			le.entry.ReversalOf = posted[le.reversalOf]
+		}
+		batch = append(batch, le)
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	for _, la := range lf.accounts {
+		if la.Inactive && !existing[la.Code].Inactive {
+			if err := bk.DeactivateAccount(la.Code); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(glOpts.output, "%d accounts, %d entries imported\n", len(lf.accounts), len(lf.entries))
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func ledgerDate(y int, m time.Month, d int) sql.NullTime {
+	return sql.NullTime{Time: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), Valid: true}
+}
+
+func Test_writeLedger_parseLedger(t *testing.T) {
+	accs := []bookkeeping.Account{
+		{Code: 1110, Name: "現金及び預金", IsBS: true, IsLeft: true},
+		{Code: 3100, Name: "資本金", IsBS: true},
+		{Code: 4100, Name: "商品売上高"},
+		{Code: 7390, Name: "雑費  旧", IsLeft: true, Inactive: true},
+		{Code: 7391, Name: `雑費;旧\1`, IsLeft: true},
+	}
+	localNames := map[int]map[string]string{
+		1110: {"en": "Cash and Deposits", "zh": "现金"},
+		7391: {"en": "Misc; old"},
+	}
+	entries := []bookkeeping.Entry{
+		{ID: 1, Date: ledgerDate(2024, 1, 5), Memo: "会社設立; 第1期", Journals: []bookkeeping.Journal{
+			{Code: 1110, Currency: "JPY", Left: 500000, FuncLeft: 500000, Description: "会社設立"},
+			{Code: 3100, Currency: "JPY", Right: 500000, FuncRight: 500000},
+		}},
+		{ID: 2, Date: ledgerDate(2024, 2, 1), Journals: []bookkeeping.Journal{
+			{Code: 1110, Currency: "USD", Left: 10050, FuncLeft: 15100, Description: "wire; ref 12"},
+			{Code: 4100, Currency: "USD", Right: 10050, FuncRight: 15100},
+		}},
+		{ID: 3, Date: ledgerDate(2024, 2, 2), ReversalOf: 2, Journals: []bookkeeping.Journal{
+			{Code: 1110, Currency: "USD", Right: 10050, FuncRight: 15100},
+			{Code: 4100, Currency: "USD", Left: 10050, FuncLeft: 15100},
+		}},
+		{ID: 4, Date: ledgerDate(2024, 12, 31), Closing: true, Journals: []bookkeeping.Journal{
+			{Code: 7390, Currency: "JPY", Right: 300, FuncRight: 300},
+			{Code: 3100, Currency: "JPY", Left: 300, FuncLeft: 300, Description: "Net loss"},
+		}},
+		{ID: 5, Date: ledgerDate(2025, 1, 10), Memo: `雑費; 旧\1`, Journals: []bookkeeping.Journal{
+			{Code: 7391, Currency: "JPY", Left: 800, FuncLeft: 800, Description: `a;b\c`},
+			{Code: 1110, Currency: "JPY", Right: 800, FuncRight: 800},
+		}},
+	}
+	names := make(map[int]string)
+	for _, a := range accs {
+		names[a.Code] = a.Name
+	}
+	for i := range entries {
+		for k := range entries[i].Journals {
+			entries[i].Journals[k].Account.Name = names[entries[i].Journals[k].Code]
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeLedger(&buf, accs, localNames, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	lf, err := parseLedger(&buf, nil)
+	if err != nil {
+		t.Fatalf("parseLedger() error = %v\n%s", err, buf.String())
+	}
+
+	gotAccs := []bookkeeping.Account{}
+	gotNames := map[int]map[string]string{}
+	for _, a := range lf.accounts {
+		if !a.typed {
+			t.Errorf("account %d must have type", a.Code)
+		}
+		gotAccs = append(gotAccs, a.Account)
+		if a.names != nil {
+			gotNames[a.Code] = a.names
+		}
+	}
+	if !reflect.DeepEqual(gotNames, localNames) {
+		t.Errorf("localized names = %+v, want %+v", gotNames, localNames)
+	}
+	if !reflect.DeepEqual(gotAccs, accs) {
+		t.Errorf("accounts = %+v, want %+v", gotAccs, accs)
+	}
+
+	if len(lf.entries) != len(entries) {
+		t.Fatalf("entries = %d, want %d", len(lf.entries), len(entries))
+	}
+	for i, le := range lf.entries {
+		want := entries[i]
+		if le.id != want.ID || le.reversalOf != want.ReversalOf {
+			t.Errorf("entry #%d id = %d, reversal of = %d, want %d, %d", i+1, le.id, le.reversalOf, want.ID, want.ReversalOf)
+		}
+		got := le.entry
+		if !got.Date.Time.Equal(want.Date.Time) || got.Memo != want.Memo || got.Closing != want.Closing {
+			t.Errorf("entry #%d = %+v, want %+v", i+1, got, want)
+		}
+		for k, j := range want.Journals {
+			want.Journals[k].Account = bookkeeping.Account{}
+			// functional amounts of journals in the functional currency are set on posting
+			if j.Currency == bookkeeping.DefaultCurrency {
+				want.Journals[k].FuncLeft, want.Journals[k].FuncRight = 0, 0
+			}
+		}
+		if !reflect.DeepEqual(got.Journals, want.Journals) {
+			t.Errorf("entry #%d journals = %+v, want %+v", i+1, got.Journals, want.Journals)
+		}
+	}
+}
+
+func Test_parseLedger(t *testing.T) {
+	accs := []bookkeeping.Account{
+		{Code: 1110, Name: "現金及び預金"},
+		{Code: 7300, Name: "旅費交通費"},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []bookkeeping.Journal
+		wantErr bool
+	}{
+		{"inferred amount and account names", "2024/01/10 電車代\n  旅費交通費  1200  ; JR東日本\n  現金及び預金\n",
+			[]bookkeeping.Journal{
+				{Code: 7300, Currency: "JPY", Left: 1200, Description: "JR東日本"},
+				{Code: 1110, Currency: "JPY", Right: 1200},
+			}, false},
+		{"account directive name and tab separator", "account 1111 外貨預金\n\n2024-02-01\n\t外貨預金\t-12.50USD\n\t7300\n",
+			[]bookkeeping.Journal{
+				{Code: 1111, Currency: "USD", Right: 1250},
+				{Code: 7300, Currency: "USD", Left: 1250},
+			}, false},
+		{"unknown account", "2024/01/10\n  交際費  1200\n  1110\n", nil, true},
+		{"two amounts omitted", "2024/01/10\n  7300\n  1110\n", nil, true},
+		{"posting without header", "  7300  1200\n", nil, true},
+		{"inferred from multiple currencies", "2024/01/10\n  7300  1200\n  7300  10USD\n  1110\n", nil, true},
+		{"invalid date", "2024/13/10\n  7300  1200\n  1110\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf, err := parseLedger(strings.NewReader(tt.input), accs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLedger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(lf.entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(lf.entries))
+			}
+			if got := lf.entries[0].entry.Journals; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("journals = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
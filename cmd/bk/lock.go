@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func lockCmd() command {
+	fset := flag.NewFlagSet("bk lock", flag.ExitOnError)
+	opts := &lockOpts{}
+	fset.Var(&dateFlag{&opts.through}, "through", "Lock entries dated on or before the date. (format: yyyymmdd)")
+
+	return command{
+		name:        "lock",
+		description: "Lock the books through date",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return lock(opts, glOpts)
+		},
+	}
+}
+
+type lockOpts struct {
+	through time.Time
+}
+
+func lock(opts *lockOpts, glOpts *globalOpts) error {
+	if opts.through.IsZero() {
+		return fmt.Errorf("-through is required")
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	if err := bk.Lock(opts.through); err != nil {
+		return err
+	}
+
+	return printLockedThrough(bk, glOpts)
+}
+
+func unlockCmd() command {
+	fset := flag.NewFlagSet("bk unlock", flag.ExitOnError)
+
+	return command{
+		name:        "unlock",
+		description: "Unlock the books except closed fiscal years",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return unlock(glOpts)
+		},
+	}
+}
+
+func unlock(glOpts *globalOpts) error {
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	if err := bk.Unlock(); err != nil {
+		return err
+	}
+
+	return printLockedThrough(bk, glOpts)
+}
+
+func printLockedThrough(bk *bookkeeping.Bookkeeping, glOpts *globalOpts) error {
+	locked, err := bk.LockedThrough()
+	if err != nil {
+		return err
+	}
+
+	if locked.IsZero() {
+		fmt.Fprintln(glOpts.output, "books are not locked")
+		return nil
+	}
+	fmt.Fprintf(glOpts.output, "books are locked through %s\n", locked.Format("2006/01/02"))
+	return nil
+}
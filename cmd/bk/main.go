@@ -17,38 +17,69 @@ func main() {
 	os.Exit(exitCode)
 }
 
-var (
-	databaseName string = "bookkeeping.db"
+const (
+	// defaultBook is the book used when no book is selected, which is the database before multiple books.
+	defaultBook = "bookkeeping"
+	bookExt     = ".db"
 )
 
 func cli() int {
 	commands := []command{
+		initCmd(),
 		accountCmd(),
 		postCmd(),
+		entryCmd(),
+		reverseCmd(),
+		importCmd(),
+		exportCmd(),
 		glCmd(),
 		bsCmd(),
 		plCmd(),
+		tbCmd(),
+		bookCmd(),
+		fxCmd(),
+		bankCmd(),
+		arCmd(),
+		fiscalCmd(),
+		closeCmd(),
+		lockCmd(),
+		unlockCmd(),
+		reconcileCmd(),
+		dbCmd(),
 		deletedbCmd(),
 	}
 
 	fset := flag.NewFlagSet("bk", flag.ExitOnError)
 	version := fset.Bool("version", false, "Print version")
 
-	glOpts := &globalOpts{
-		output: os.Stdout,
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Print(err)
+		return 1
 	}
 
-	homeDir, err := os.UserHomeDir()
+	conf, err := loadConfig(filepath.Join(homeDir, ".bookkeeping", configFileName))
 	if err != nil {
 		log.Print(err)
 		return 1
 	}
 
-	glOpts.dataDir = filepath.Join(homeDir, ".bookkeeping")
+	glOpts := &globalOpts{
+		input:  os.Stdin,
+		output: os.Stdout,
+	}
+	fset.StringVar(&glOpts.format, "format", firstNonEmpty(conf.format, "text"), "Output format. (text, csv, tsv or json)")
+	fset.StringVar(&glOpts.book, "book", firstNonEmpty(os.Getenv("BK_BOOK"), conf.book, defaultBook),
+		"Book to use, which is a separate database in the data directory. (env: BK_BOOK)")
+	fset.StringVar(&glOpts.locale, "lang", conf.locale, "Language of account names and report labels, e.g. ja or en. (default: names as added and English labels)")
+	fset.StringVar(&glOpts.dataDir, "data-dir", firstNonEmpty(os.Getenv("BK_DATA_DIR"), conf.dataDir, filepath.Join(homeDir, ".bookkeeping")),
+		"Directory of book databases. (env: BK_DATA_DIR)")
 
 	fset.Usage = func() {
 		fmt.Fprintln(fset.Output(), "Usage: bk <command> [command flags]")
 		fset.PrintDefaults()
+		fmt.Fprintln(fset.Output())
+		fmt.Fprintf(fset.Output(), "Defaults of data-dir, book, format and locale can be set in %s\n", filepath.Join(homeDir, ".bookkeeping", configFileName))
 
 		fmt.Fprintln(fset.Output())
 		fmt.Fprintln(fset.Output(), "Commands:")
@@ -68,6 +99,16 @@ func cli() int {
 		return 0
 	}
 
+	if err := validateFormat(glOpts.format); err != nil {
+		fmt.Fprintln(fset.Output(), err)
+		return 1
+	}
+
+	if err := validateBookName(glOpts.book); err != nil {
+		fmt.Fprintln(fset.Output(), err)
+		return 1
+	}
+
 	args := fset.Args()
 	if len(args) == 0 {
 		fset.Usage()
@@ -107,7 +148,17 @@ func subcmd(parentCmd string, subCommands []command, args []string, glOpts *glob
 
 type globalOpts struct {
 	dataDir string
-	output  io.Writer
+	book    string
+	format  string
+	// locale is the language of localized output, e.g. "ja" or "en".
+	locale string
+	input  io.Reader
+	output io.Writer
+}
+
+// dbPath returns the database file path of the selected book.
+func (o *globalOpts) dbPath() string {
+	return bookPath(o.dataDir, o.book)
 }
 
 type command struct {
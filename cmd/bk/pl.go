@@ -4,7 +4,6 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -16,6 +15,7 @@ func plCmd() command {
 	opts := &plOpts{}
 	fset.Var(&dateFlag{&opts.startDate}, "start", "start date of P&L time period. (format: yyyymmdd)")
 	fset.Var(&dateFlag{&opts.endDate}, "end", "end date of P&L time period. (format: yyyymmdd)")
+	fset.BoolVar(&opts.detail, "detail", false, "Show balance of each account.")
 
 	return command{
 		name:        "pl",
@@ -31,81 +31,159 @@ func plCmd() command {
 type plOpts struct {
 	startDate time.Time
 	endDate   time.Time
+	detail    bool
 }
 
 func pl(opts *plOpts, glOpts *globalOpts) error {
 
-	db, err := bookkeeping.NewDB(filepath.Join(glOpts.dataDir, databaseName))
+	db, err := openBook(glOpts)
 	if err != nil {
 		return err
 	}
 	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
 
 	fetchPLOpts := bookkeeping.FetchPLOpts{
 		Start: opts.startDate,
 		End:   opts.endDate,
 	}
 
+	if opts.detail {
+		items, err := bk.FetchPLDetail(fetchPLOpts)
+		if err != nil {
+			return err
+		}
+
+		return render(glOpts, report{
+			data:  items,
+			text:  func(w io.Writer) { printPLDetail(w, glOpts.locale, items) },
+			table: func() [][]string { return plDetailTable(items) },
+		})
+	}
+
 	items, err := bk.FetchPL(fetchPLOpts)
 	if err != nil {
 		return err
 	}
 
-	printPL(glOpts.output, items)
+	return render(glOpts, report{
+		data:  items,
+		text:  func(w io.Writer) { printPL(w, glOpts.locale, items) },
+		table: func() [][]string { return plTable(items) },
+	})
+}
+
+func plTable(pl bookkeeping.PL) [][]string {
+	return [][]string{
+		{"description", "amount"},
+		{"Net Sales", bookkeeping.FormatAmount(pl.NetSales, pl.Currency)},
+		{"Cost Sales", bookkeeping.FormatAmount(pl.CostSales, pl.Currency)},
+		{"Gross Profit", bookkeeping.FormatAmount(pl.GrossProfit, pl.Currency)},
+		{"Operating Expences", bookkeeping.FormatAmount(pl.OperatingExpences, pl.Currency)},
+		{"Operating Income", bookkeeping.FormatAmount(pl.OperatingIncome, pl.Currency)},
+		{"Non Operating Incomes", bookkeeping.FormatAmount(pl.NonOperatingIncomes, pl.Currency)},
+		{"Non Operating Expences", bookkeeping.FormatAmount(pl.NonOperatingExpences, pl.Currency)},
+		{"Extraordinary Incomes", bookkeeping.FormatAmount(pl.ExtraordinaryIncomes, pl.Currency)},
+		{"Extraordinary Expences", bookkeeping.FormatAmount(pl.ExtraordinaryExpences, pl.Currency)},
+		{"Income Before Provision For Income Taxes", bookkeeping.FormatAmount(pl.IncomeBeforeProvisionForIncomeTaxes, pl.Currency)},
+		{"Provision For Income Taxes", bookkeeping.FormatAmount(pl.ProvisionForIncomeTaxes, pl.Currency)},
+		{"Net Income", bookkeeping.FormatAmount(pl.NetIncome, pl.Currency)},
+	}
+}
 
-	return nil
+func plDetailTable(pl bookkeeping.PLDetail) [][]string {
+	rows := [][]string{{"section", "section name", "code", "name", "amount"}}
+	for _, sec := range pl.Sections {
+		rows = append(rows, sectionRows(sec, pl.Currency)...)
+	}
+	return rows
 }
 
-func printPL(w io.Writer, pl bookkeeping.PL) {
-	fmt.Fprintln(w, "Profit and Loss Statement:")
+func printPL(w io.Writer, lang string, pl bookkeeping.PL) {
+	fmt.Fprintln(w, label(lang, "Profit and Loss Statement")+":")
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "description", 45)
-	fprintRFW(w, "amount", 20)
+	fprintLFW(w, label(lang, "description"), 45)
+	fprintRFW(w, label(lang, "amount")+" ("+pl.Currency+")", 20)
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, strings.Repeat("-", 70))
 
-	fprintLFW(w, "Net Sales", 45)
-	fprintRFW(w, pl.NetSales, 20)
+	fprintLFW(w, label(lang, "Net Sales"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.NetSales, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Cost Sales", 45)
-	fprintRFW(w, pl.CostSales, 20)
+	fprintLFW(w, label(lang, "Cost Sales"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.CostSales, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Gross Profit", 45)
-	fprintRFW(w, pl.GrossProfit, 20)
+	fprintLFW(w, label(lang, "Gross Profit"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.GrossProfit, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Operating Expences", 45)
-	fprintRFW(w, pl.OperatingExpences, 20)
+	fprintLFW(w, label(lang, "Operating Expences"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.OperatingExpences, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Operating Income", 45)
-	fprintRFW(w, pl.OperatingIncome, 20)
+	fprintLFW(w, label(lang, "Operating Income"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.OperatingIncome, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Non Operating Incomes", 45)
-	fprintRFW(w, pl.NonOperatingIncomes, 20)
+	fprintLFW(w, label(lang, "Non Operating Incomes"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.NonOperatingIncomes, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Non Operating Expences", 45)
-	fprintRFW(w, pl.NonOperatingExpences, 20)
+	fprintLFW(w, label(lang, "Non Operating Expences"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.NonOperatingExpences, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Extraordinary Incomes", 45)
-	fprintRFW(w, pl.ExtraordinaryIncomes, 20)
+	fprintLFW(w, label(lang, "Extraordinary Incomes"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.ExtraordinaryIncomes, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Extraordinary Expences", 45)
-	fprintRFW(w, pl.ExtraordinaryExpences, 20)
+	fprintLFW(w, label(lang, "Extraordinary Expences"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.ExtraordinaryExpences, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Income Before Provision For Income Taxes", 45)
-	fprintRFW(w, pl.IncomeBeforeProvisionForIncomeTaxes, 20)
+	fprintLFW(w, label(lang, "Income Before Provision For Income Taxes"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.IncomeBeforeProvisionForIncomeTaxes, pl.Currency), 20)
 	fmt.Fprintln(w)
 
-	fprintLFW(w, "Net Income", 45)
-	fprintRFW(w, pl.NetIncome, 20)
+	fprintLFW(w, label(lang, "Net Income"), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(pl.NetIncome, pl.Currency), 20)
 	fmt.Fprintln(w)
 }
+
+func printPLDetail(w io.Writer, lang string, pl bookkeeping.PLDetail) {
+	fmt.Fprintln(w, label(lang, "Profit and Loss Statement")+":")
+	fmt.Fprintln(w)
+
+	fprintLFW(w, label(lang, "description"), 45)
+	fprintRFW(w, label(lang, "amount")+" ("+pl.Currency+")", 20)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 70))
+
+	for _, sec := range pl.Sections {
+		printSection(w, lang, sec, pl.Currency, 0)
+
+		var name string
+		var amount int
+		switch sec.Prefix {
+		case "6":
+			name, amount = label(lang, "Gross Profit"), pl.GrossProfit
+		case "7":
+			name, amount = label(lang, "Operating Income"), pl.OperatingIncome
+		case "84":
+			name, amount = label(lang, "Income Before Provision For Income Taxes"), pl.IncomeBeforeProvisionForIncomeTaxes
+		case "9":
+			name, amount = label(lang, "Net Income"), pl.NetIncome
+		default:
+			continue
+		}
+
+		fmt.Fprintln(w)
+		fprintLFW(w, name, 45)
+		fprintRFW(w, bookkeeping.FormatAmount(amount, pl.Currency), 20)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"flag"
 	"fmt"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -14,13 +13,15 @@ import (
 
 func postCmd() command {
 	fset := flag.NewFlagSet("bk post", flag.ExitOnError)
-	opts := &postOpts{date: time.Now()}
+	opts := &postOpts{date: today()}
 	fset.Var(&dateFlag{&opts.date}, "date", "Journal post date. (format: yyyymmdd)")
-	fset.Func("left", "Journal debit item. (format: <account code>/<amount>[/<description>]", func(v string) error {
+	fset.StringVar(&opts.memo, "memo", "", "Memo of the entry.")
+	fset.BoolVar(&opts.interactive, "i", false, "Enter the date and legs of the entry interactively.")
+	fset.Func("left", "Journal debit item. (format: <account code>/<amount>[<currency>][/<description>]", func(v string) error {
 		opts.left = append(opts.left, v)
 		return nil
 	})
-	fset.Func("right", "Journal credit item. (format: <account code>/<amount>[/<description>]", func(v string) error {
+	fset.Func("right", "Journal credit item. (format: <account code>/<amount>[<currency>][/<description>]", func(v string) error {
 		opts.right = append(opts.right, v)
 		return nil
 	})
@@ -31,6 +32,9 @@ func postCmd() command {
 		fset:        fset,
 		fn: func(args []string, glOpts *globalOpts) error {
 			fset.Parse(args)
+			if opts.interactive {
+				return postInteractive(opts, glOpts)
+			}
 			return post(opts, glOpts)
 		},
 	}
@@ -40,6 +44,9 @@ type postOpts struct {
 	left  []string
 	right []string
 	date  time.Time
+	memo  string
+
+	interactive bool
 }
 
 func post(opts *postOpts, glOpts *globalOpts) error {
@@ -51,7 +58,7 @@ func post(opts *postOpts, glOpts *globalOpts) error {
 		if err != nil {
 			return err
 		}
-		jn := bookkeeping.Journal{Code: code, Left: amnt, Description: desc, Date: sql.NullTime{Time: opts.date, Valid: true}}
+		jn := bookkeeping.Journal{Code: code, Left: amnt.Amount, Currency: amnt.Currency, Description: desc, Date: sql.NullTime{Time: opts.date, Valid: true}}
 
 		journalItems = append(journalItems, jn)
 	}
@@ -61,38 +68,44 @@ func post(opts *postOpts, glOpts *globalOpts) error {
 		if err != nil {
 			return err
 		}
-		jn := bookkeeping.Journal{Code: code, Right: amnt, Description: desc, Date: sql.NullTime{Time: opts.date, Valid: true}}
+		jn := bookkeeping.Journal{Code: code, Right: amnt.Amount, Currency: amnt.Currency, Description: desc, Date: sql.NullTime{Time: opts.date, Valid: true}}
 
 		journalItems = append(journalItems, jn)
 	}
 
-	db, err := bookkeeping.NewDB(filepath.Join(glOpts.dataDir, databaseName))
+	db, err := openBook(glOpts)
 	if err != nil {
 		return err
 	}
 	bk := bookkeeping.NewBookkeeping(db)
 
-	err = bk.Post(journalItems)
+	id, err := bk.PostEntry(bookkeeping.Entry{
+		Date:     sql.NullTime{Time: opts.date, Valid: true},
+		Memo:     opts.memo,
+		Journals: journalItems,
+	})
 	if err != nil {
 		return err
 	}
+
+	fmt.Fprintf(glOpts.output, "entry %d posted\n", id)
 	return nil
 }
 
-func parseJournalItem(s string) (accCode int, amount int, desc string, err error) {
+func parseJournalItem(s string) (accCode int, amount bookkeeping.Money, desc string, err error) {
 	cols := strings.Split(s, "/")
 	if len(cols) < 2 || len(cols) > 3 {
-		return 0, 0, "", fmt.Errorf("cannot parse '%s' as journal item format, format: <account code>/<amount>[/<description>]", s)
+		return 0, amount, "", fmt.Errorf("cannot parse '%s' as journal item format, format: <account code>/<amount>[<currency>][/<description>]", s)
 	}
 
 	code, err := strconv.Atoi(cols[0])
 	if err != nil {
-		return 0, 0, "", fmt.Errorf("cannot parse '%s' as account code: %w", cols[0], err)
+		return 0, amount, "", fmt.Errorf("cannot parse '%s' as account code: %w", cols[0], err)
 	}
 
-	a, err := strconv.Atoi(cols[1])
+	a, err := bookkeeping.ParseMoney(cols[1])
 	if err != nil {
-		return 0, 0, "", fmt.Errorf("cannot parse '%s' as amount: %w", cols[1], err)
+		return 0, amount, "", err
 	}
 
 	d := ""
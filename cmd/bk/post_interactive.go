@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+// maxCandidates is the number of candidate accounts shown when an account input matches several accounts.
+const maxCandidates = 20
+
+// errCanceled is returned by prompt when the input ends.
+var errCanceled = errors.New("canceled")
+
+// postInteractive asks the date, the memo and the legs of an entry line by line,
+// and posts the entry once debit and credit balance.
+func postInteractive(opts *postOpts, glOpts *globalOpts) error {
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
+
+	accs, err := bk.FetchAc(bookkeeping.FetchAcOpts{})
+	if err != nil {
+		return err
+	}
+	active := make([]bookkeeping.Account, 0, len(accs))
+	for _, a := range accs {
+		if !a.Inactive {
+			active = append(active, a)
+		}
+	}
+
+	p := &prompter{in: bufio.NewScanner(glOpts.input), out: glOpts.output}
+	e, err := askEntry(p, bk, active, opts)
+	if err == errCanceled {
+		fmt.Fprintln(glOpts.output)
+		fmt.Fprintln(glOpts.output, "canceled")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	id, err := bk.PostEntry(e)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "entry %d posted\n", id)
+	return nil
+}
+
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask prints the question with the default answer, and returns the trimmed answer or the default if empty.
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+
+	if !p.in.Scan() {
+		if err := p.in.Err(); err != nil {
+			return "", err
+		}
+		return "", errCanceled
+	}
+
+	answer := strings.TrimSpace(p.in.Text())
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+func askEntry(p *prompter, bk *bookkeeping.Bookkeeping, accs []bookkeeping.Account, opts *postOpts) (bookkeeping.Entry, error) {
+	e := bookkeeping.Entry{}
+
+	for !e.Date.Valid {
+		s, err := p.ask("date (yyyymmdd)", opts.date.Format("20060102"))
+		if err != nil {
+			return e, err
+		}
+		d, err := time.Parse("20060102", s)
+		if err != nil {
+			fmt.Fprintf(p.out, "cannot parse '%s' as date, format: yyyymmdd\n", s)
+			continue
+		}
+		e.Date = sql.NullTime{Time: d, Valid: true}
+	}
+
+	memo, err := p.ask("memo", opts.memo)
+	if err != nil {
+		return e, err
+	}
+	e.Memo = memo
+
+	fmt.Fprintln(p.out, "enter legs of the entry, an empty account to finish")
+	for {
+		a, ok, err := askAccount(p, accs, len(e.Journals)+1)
+		if err != nil {
+			return e, err
+		}
+
+		if !ok {
+			err := bk.CheckEntry(e)
+			if err == nil {
+				return e, nil
+			}
+			fmt.Fprintf(p.out, "cannot post yet: %v\n", err)
+			continue
+		}
+
+		j, err := askLeg(p, a, e.Journals)
+		if err != nil {
+			return e, err
+		}
+		j.Date = e.Date
+		e.Journals = append(e.Journals, j)
+
+		for _, t := range runningTotals(e.Journals) {
+			fmt.Fprintf(p.out, "  %s debit: %s, credit: %s, difference: %s\n", t.currency,
+				bookkeeping.FormatAmount(t.debit, t.currency), bookkeeping.FormatAmount(t.credit, t.currency),
+				bookkeeping.FormatAmount(t.debit-t.credit, t.currency))
+		}
+	}
+}
+
+// askAccount asks the account of the n-th leg until the input matches a single account.
+// It returns false if the input is empty.
+func askAccount(p *prompter, accs []bookkeeping.Account, n int) (bookkeeping.Account, bool, error) {
+	for {
+		s, err := p.ask(fmt.Sprintf("leg %d account (code, code prefix or name)", n), "")
+		if err != nil || s == "" {
+			return bookkeeping.Account{}, false, err
+		}
+
+		candidates := completeAccounts(accs, s)
+		switch {
+		case len(candidates) == 0:
+			fmt.Fprintf(p.out, "no account matches '%s'\n", s)
+		case len(candidates) == 1:
+			fmt.Fprintf(p.out, "  %d %s\n", candidates[0].Code, candidates[0].Name)
+			return candidates[0], true, nil
+		default:
+			for i, a := range candidates {
+				if i == maxCandidates {
+					fmt.Fprintf(p.out, "  ... and %d more\n", len(candidates)-maxCandidates)
+					break
+				}
+				fmt.Fprintf(p.out, "  %d %s\n", a.Code, a.Name)
+			}
+		}
+	}
+}
+
+// askLeg asks the side, the amount and the description of a leg to the account.
+// The default side and amount are the ones which balance the journals so far.
+func askLeg(p *prompter, a bookkeeping.Account, jn []bookkeeping.Journal) (bookkeeping.Journal, error) {
+	j := bookkeeping.Journal{Code: a.Code}
+
+	side, remaining := "d", bookkeeping.Money{}
+	if !a.IsLeft {
+		side = "c"
+	}
+	totals := runningTotals(jn)
+	if len(totals) == 1 && totals[0].debit != totals[0].credit {
+		diff := totals[0].debit - totals[0].credit
+		side, remaining = "c", bookkeeping.Money{Amount: diff, Currency: totals[0].currency}
+		if diff < 0 {
+			side, remaining.Amount = "d", -diff
+		}
+	}
+
+	for {
+		s, err := p.ask("debit or credit (d/c)", side)
+		if err != nil {
+			return j, err
+		}
+		if s == "d" || s == "c" {
+			side = s
+			break
+		}
+		fmt.Fprintln(p.out, "answer 'd' for debit or 'c' for credit")
+	}
+
+	def := ""
+	if remaining.Amount > 0 {
+		def = remaining.String()
+	}
+	for {
+		s, err := p.ask("amount", def)
+		if err != nil {
+			return j, err
+		}
+		m, err := bookkeeping.ParseMoney(s)
+		if err != nil || m.Amount <= 0 {
+			fmt.Fprintf(p.out, "cannot parse '%s' as amount, format: <amount>[<currency code>]\n", s)
+			continue
+		}
+
+		j.Currency = m.Currency
+		if side == "d" {
+			j.Left = m.Amount
+		} else {
+			j.Right = m.Amount
+		}
+		break
+	}
+
+	desc, err := p.ask("description", "")
+	if err != nil {
+		return j, err
+	}
+	j.Description = desc
+
+	return j, nil
+}
+
+// completeAccounts returns the accounts of the exact code, or else the accounts whose code starts with s
+// or whose name contains s.
+func completeAccounts(accs []bookkeeping.Account, s string) []bookkeeping.Account {
+	res := []bookkeeping.Account{}
+	for _, a := range accs {
+		code := strconv.Itoa(a.Code)
+		if code == s {
+			return []bookkeeping.Account{a}
+		}
+		if strings.HasPrefix(code, s) || strings.Contains(strings.ToLower(a.Name), strings.ToLower(s)) {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+type currencyTotal struct {
+	currency string
+	debit    int
+	credit   int
+}
+
+// runningTotals sums debit and credit of the journals by currency, in the order of appearance.
+func runningTotals(jn []bookkeeping.Journal) []currencyTotal {
+	res := []currencyTotal{}
+	idx := make(map[string]int)
+	for _, j := range jn {
+		c := j.Currency
+		if c == "" {
+			c = bookkeeping.DefaultCurrency
+		}
+		i, ok := idx[c]
+		if !ok {
+			i = len(res)
+			idx[c] = i
+			res = append(res, currencyTotal{currency: c})
+		}
+		res[i].debit += j.Left
+		res[i].credit += j.Right
+	}
+	return res
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_completeAccounts(t *testing.T) {
+	accs := []bookkeeping.Account{
+		{Code: 1110, Name: "現金及び預金"},
+		{Code: 1120, Name: "売掛金"},
+		{Code: 2100, Name: "買掛金"},
+		{Code: 7300, Name: "Travel Expenses"},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []int
+	}{
+		{"exact code", "1110", []int{1110}},
+		{"code prefix", "11", []int{1110, 1120}},
+		{"name", "掛金", []int{1120, 2100}},
+		{"name, case insensitive", "travel", []int{7300}},
+		{"no match", "9", []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []int{}
+			for _, a := range completeAccounts(accs, tt.input) {
+				got = append(got, a.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("completeAccounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_askLeg(t *testing.T) {
+	cash := bookkeeping.Account{Code: 1110, Name: "現金及び預金", IsBS: true, IsLeft: true}
+	capital := bookkeeping.Account{Code: 3100, Name: "資本金", IsBS: true}
+
+	tests := []struct {
+		name    string
+		account bookkeeping.Account
+		jn      []bookkeeping.Journal
+		input   string
+		want    bookkeeping.Journal
+	}{
+		{"normal side of the account", cash, nil, "\n500000\n会社設立\n",
+			bookkeeping.Journal{Code: 1110, Left: 500000, Currency: "JPY", Description: "会社設立"}},
+		{"defaults balance the journals", capital, []bookkeeping.Journal{{Code: 1110, Left: 500000}}, "\n\n\n",
+			bookkeeping.Journal{Code: 3100, Right: 500000, Currency: "JPY"}},
+		{"invalid answers are asked again", cash, nil, "x\nc\n-1\n10.50USD\n\n",
+			bookkeeping.Journal{Code: 1110, Right: 1050, Currency: "USD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &prompter{in: bufio.NewScanner(strings.NewReader(tt.input)), out: io.Discard}
+			got, err := askLeg(p, tt.account, tt.jn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("askLeg() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	p := &prompter{in: bufio.NewScanner(strings.NewReader("d\n")), out: io.Discard}
+	if _, err := askLeg(p, cash, nil); err != errCanceled {
+		t.Errorf("askLeg() error = %v, want %v", err, errCanceled)
+	}
+}
+
+func Test_runningTotals(t *testing.T) {
+	jn := []bookkeeping.Journal{
+		{Code: 1110, Left: 500000},
+		{Code: 1110, Left: 1000, Currency: "USD"},
+		{Code: 3100, Right: 300000, Currency: "JPY"},
+	}
+	want := []currencyTotal{
+		{currency: "JPY", debit: 500000, credit: 300000},
+		{currency: "USD", debit: 1000},
+	}
+	if got := runningTotals(jn); !reflect.DeepEqual(got, want) {
+		t.Errorf("runningTotals() = %+v, want %+v", got, want)
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
 
 func Test_parseJournalItem(t *testing.T) {
 	type args struct {
@@ -10,30 +14,36 @@ func Test_parseJournalItem(t *testing.T) {
 		name       string
 		args       args
 		wantAccID  int
-		wantAmount int
+		wantAmount bookkeeping.Money
 		wantDesc   string
 		wantErr    bool
 	}{
 		{"ok, without description", args{"23/5000"},
-			23, 5000, "", false,
+			23, bookkeeping.Money{Amount: 5000, Currency: "JPY"}, "", false,
 		},
 		{"ok, with description", args{"23/5000/foo bar"},
-			23, 5000, "foo bar", false,
+			23, bookkeeping.Money{Amount: 5000, Currency: "JPY"}, "foo bar", false,
+		},
+		{"ok, with currency", args{"23/12.50USD/foo bar"},
+			23, bookkeeping.Money{Amount: 1250, Currency: "USD"}, "foo bar", false,
 		},
 		{"error, missing amount and separator", args{"23"},
-			0, 0, "", true,
+			0, bookkeeping.Money{}, "", true,
 		},
 		{"error, missing amount", args{"23/"},
-			0, 0, "", true,
+			0, bookkeeping.Money{}, "", true,
 		},
 		{"error, missing id", args{"/9999"},
-			0, 0, "", true,
+			0, bookkeeping.Money{}, "", true,
 		},
 		{"error, wrong id format", args{"abc/9999"},
-			0, 0, "", true,
+			0, bookkeeping.Money{}, "", true,
 		},
 		{"error, wrong amount format", args{"12/9999ab"},
-			0, 0, "", true,
+			0, bookkeeping.Money{}, "", true,
+		},
+		{"error, unsupported currency", args{"12/10XYZ"},
+			0, bookkeeping.Money{}, "", true,
 		},
 	}
 	for _, tt := range tests {
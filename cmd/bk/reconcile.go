@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func reconcileCmd() command {
+	fset := flag.NewFlagSet("bk reconcile", flag.ExitOnError)
+	opts := &reconcileOpts{}
+	fset.IntVar(&opts.code, "account", 0, "Account code of the bank account. e.g. 1110")
+	fset.Var(&dateFlag{&opts.statementDate}, "statement-date", "Closing date of the bank statement. (format: yyyymmdd)")
+	fset.StringVar(&opts.statementBalance, "statement-balance", "", "Closing balance of the bank statement. e.g. 1293800 or -500")
+	fset.StringVar(&opts.ref, "ref", "", "Reference of the bank statement. (default the statement date)")
+	fset.StringVar(&opts.tick, "tick", "", "Comma separated journal IDs cleared on the statement, or 'all'. Asks interactively if omitted.")
+	fset.BoolVar(&opts.dryRun, "dry-run", false, "Report the difference without reconciling.")
+
+	return command{
+		name:        "reconcile",
+		description: "Reconcile bank account journals with the bank statement",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return reconcile(opts, glOpts)
+		},
+	}
+}
+
+type reconcileOpts struct {
+	code             int
+	statementDate    time.Time
+	statementBalance string
+	ref              string
+	tick             string
+	dryRun           bool
+}
+
+func reconcile(opts *reconcileOpts, glOpts *globalOpts) error {
+	if opts.code == 0 {
+		return fmt.Errorf("-account is required")
+	}
+	if opts.statementDate.IsZero() {
+		return fmt.Errorf("-statement-date is required")
+	}
+	if opts.statementBalance == "" {
+		return fmt.Errorf("-statement-balance is required")
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
+
+	ropts := bookkeeping.ReconcileOpts{
+		Code:          opts.code,
+		StatementDate: opts.statementDate,
+		StatementRef:  opts.ref,
+	}
+	// the currency of the statement balance is the currency of the account
+	r, err := bk.FetchReconciliation(ropts)
+	if err != nil {
+		return err
+	}
+	if ropts.StatementBalance, err = parseStatementBalance(opts.statementBalance, r.Currency); err != nil {
+		return err
+	}
+
+	if opts.tick == "" {
+		return reconcileInteractive(bk, ropts, opts.dryRun, glOpts)
+	}
+
+	ropts.Tick, err = parseTicks(opts.tick, r.Uncleared)
+	if err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		r, err = bk.FetchReconciliation(ropts)
+	} else {
+		r, err = bk.Reconcile(ropts)
+	}
+	if r.Account.Code == 0 {
+		return err
+	}
+
+	if rerr := render(glOpts, report{
+		data:  r,
+		text:  func(w io.Writer) { printReconciliation(w, r) },
+		table: func() [][]string { return reconciliationTable(r) },
+	}); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// reconcileInteractive prints the uncleared journals, and toggles the ticks of the journal IDs answered
+// until the cleared balance agrees with the statement balance.
+func reconcileInteractive(bk *bookkeeping.Bookkeeping, opts bookkeeping.ReconcileOpts, dryRun bool, glOpts *globalOpts) error {
+	p := &prompter{in: bufio.NewScanner(glOpts.input), out: glOpts.output}
+
+	for {
+		r, err := bk.FetchReconciliation(opts)
+		if err != nil {
+			return err
+		}
+		printReconciliation(glOpts.output, r)
+		fmt.Fprintln(glOpts.output)
+
+		question := "Tick journal IDs ('all', 'none', 'q' to quit, empty to reconcile)"
+		if dryRun {
+			question = "Tick journal IDs ('all', 'none', 'q' to quit)"
+		}
+		a, err := p.ask(question, "")
+		if err == errCanceled || a == "q" {
+			fmt.Fprintln(glOpts.output)
+			fmt.Fprintln(glOpts.output, "canceled")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if a == "" && !dryRun {
+			if r.Difference != 0 {
+				fmt.Fprintf(glOpts.output, "difference %s must be 0 to reconcile\n\n", bookkeeping.FormatAmount(r.Difference, r.Currency))
+				continue
+			}
+			if _, err := bk.Reconcile(opts); err != nil {
+				return err
+			}
+			fmt.Fprintf(glOpts.output, "%d journals reconciled on statement %s\n", len(r.Tick), r.StatementRef)
+			return nil
+		}
+
+		ticks, err := parseTicks(a, r.Uncleared)
+		if err != nil {
+			fmt.Fprintf(glOpts.output, "%v\n\n", err)
+			continue
+		}
+		if a == "all" || a == "none" {
+			opts.Tick = ticks
+		} else {
+			opts.Tick = toggleTicks(r.Tick, ticks)
+		}
+	}
+}
+
+// parseTicks parses comma or space separated IDs of the uncleared journals, or 'all' for all of them.
+// 'none' is no IDs.
+func parseTicks(s string, uncleared []bookkeeping.Journal) ([]int, error) {
+	switch s {
+	case "all":
+		ids := make([]int, 0, len(uncleared))
+		for _, j := range uncleared {
+			ids = append(ids, j.ID)
+		}
+		return ids, nil
+	case "none":
+		return []int{}, nil
+	}
+
+	ids := []int{}
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		id, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse '%s' as journal ID", f)
+		}
+		found := false
+		for _, j := range uncleared {
+			found = found || j.ID == id
+		}
+		if !found {
+			return nil, fmt.Errorf("journal '%d' is not in the uncleared journals", id)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// toggleTicks returns the ticks with each of the IDs ticked if not ticked, or unticked if ticked.
+func toggleTicks(ticks, ids []int) []int {
+	res := append([]int{}, ticks...)
+	for _, id := range ids {
+		i := 0
+		for i < len(res) && res[i] != id {
+			i++
+		}
+		if i < len(res) {
+			res = append(res[:i], res[i+1:]...)
+		} else {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+// parseStatementBalance parses a statement balance in the currency, with an optional leading '-'
+// and an optional currency code.
+func parseStatementBalance(s, currency string) (int, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if s != "" && s[len(s)-1] >= '0' && s[len(s)-1] <= '9' {
+		s += currency
+	}
+
+	m, err := bookkeeping.ParseMoney(s)
+	if err != nil {
+		return 0, err
+	}
+	if m.Currency != currency {
+		return 0, fmt.Errorf("statement balance must be in %s, but got %s", currency, m.Currency)
+	}
+	if neg {
+		return -m.Amount, nil
+	}
+	return m.Amount, nil
+}
+
+func printReconciliation(w io.Writer, r bookkeeping.Reconciliation) {
+	fmt.Fprintf(w, "Reconciliation of %d %s (%s) with statement %s on %s\n",
+		r.Account.Code, r.Account.Name, r.Currency, r.StatementRef, r.StatementDate.Time.Format("2006/01/02"))
+	fmt.Fprintln(w)
+
+	fprintLFW(w, "", 4)
+	fprintLFW(w, "id", 8)
+	fprintLFW(w, "date", 12)
+	fprintLFW(w, "description", 30)
+	fprintRFW(w, "debit", 15)
+	fprintRFW(w, "credit", 15)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 84))
+
+	for _, j := range r.Uncleared {
+		mark := "[ ]"
+		if r.Ticked(j.ID) {
+			mark = "[x]"
+		}
+		fprintLFW(w, mark, 4)
+		fprintLFW(w, j.ID, 8)
+		fprintLFW(w, j.Date.Time.Format("2006/01/02"), 12)
+		fprintLFW(w, j.Description, 30)
+		fprintRFW(w, bookkeeping.FormatAmount(j.Left, r.Currency), 15)
+		fprintRFW(w, bookkeeping.FormatAmount(j.Right, r.Currency), 15)
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+
+	for _, l := range []struct {
+		name   string
+		amount int
+	}{
+		{"statement balance", r.StatementBalance},
+		{"cleared balance", r.ClearedBalance},
+		{"difference", r.Difference},
+		{"book balance", r.BookBalance},
+		{"book - statement", r.BookBalance - r.StatementBalance},
+	} {
+		fprintLFW(w, l.name, 20)
+		fprintRFW(w, bookkeeping.FormatAmount(l.amount, r.Currency), 20)
+		fmt.Fprintln(w)
+	}
+}
+
+func reconciliationTable(r bookkeeping.Reconciliation) [][]string {
+	rows := [][]string{{"id", "date", "entry", "description", "debit", "credit", "ticked", "reconciled"}}
+	for _, j := range r.Uncleared {
+		rows = append(rows, []string{
+			strconv.Itoa(j.ID), j.Date.Time.Format("2006-01-02"), strconv.Itoa(j.EntryID), j.Description,
+			bookkeeping.FormatAmount(j.Left, r.Currency), bookkeeping.FormatAmount(j.Right, r.Currency),
+			strconv.FormatBool(r.Ticked(j.ID)), strconv.FormatBool(j.Reconciled),
+		})
+	}
+	return rows
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_parseTicks(t *testing.T) {
+	uncleared := []bookkeeping.Journal{{ID: 3}, {ID: 5}, {ID: 8}}
+
+	tests := []struct {
+		name    string
+		s       string
+		want    []int
+		wantErr bool
+	}{
+		{"all", "all", []int{3, 5, 8}, false},
+		{"none", "none", []int{}, false},
+		{"comma and space separated", "3, 8", []int{3, 8}, false},
+		{"error, not a number", "3,x", nil, true},
+		{"error, not uncleared", "4", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTicks(tt.s, uncleared)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseTicks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTicks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_toggleTicks(t *testing.T) {
+	got := toggleTicks([]int{3, 5}, []int{5, 8})
+	if want := []int{3, 8}; !reflect.DeepEqual(got, want) {
+		t.Errorf("toggleTicks() = %v, want %v", got, want)
+	}
+}
+
+func Test_parseStatementBalance(t *testing.T) {
+	tests := []struct {
+		s        string
+		currency string
+		want     int
+		wantErr  bool
+	}{
+		{"1293800", "JPY", 1293800, false},
+		{"-500", "JPY", -500, false},
+		{"12.50", "USD", 1250, false},
+		{"-12.50USD", "USD", -1250, false},
+		{"12.50USD", "JPY", 0, true},
+		{"abc", "JPY", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseStatementBalance(tt.s, tt.currency)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseStatementBalance(%s, %s) error = %v, wantErr %v", tt.s, tt.currency, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseStatementBalance(%s, %s) = %v, want %v", tt.s, tt.currency, got, tt.want)
+		}
+	}
+}
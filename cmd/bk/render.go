@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+var outputFormats = []string{"text", "csv", "tsv", "json"}
+
+func validateFormat(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format: '%s'", format)
+}
+
+// report is an output of a command, which can be rendered in any of the output formats.
+type report struct {
+	// data is encoded as is in json format.
+	data interface{}
+	// text writes the report in human readable text format.
+	text func(w io.Writer)
+	// table returns rows of the report with a header row for csv and tsv format.
+	table func() [][]string
+}
+
+func render(glOpts *globalOpts, r report) error {
+	w := glOpts.output
+
+	switch glOpts.format {
+	case "", "text":
+		r.text(w)
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r.data)
+	case "csv", "tsv":
+		cw := csv.NewWriter(w)
+		if glOpts.format == "tsv" {
+			cw.Comma = '\t'
+		}
+		return cw.WriteAll(r.table())
+	default:
+		return validateFormat(glOpts.format)
+	}
+}
+
+// sectionRows returns rows of account lines and totals of the section and its subsections.
+func sectionRows(sec bookkeeping.Section, currency string) [][]string {
+	rows := [][]string{}
+	for _, l := range sec.Lines {
+		code := ""
+		if l.Account.Code > 0 {
+			code = strconv.Itoa(l.Account.Code)
+		}
+		rows = append(rows, []string{sec.Prefix, sec.Name, code, l.Account.Name, bookkeeping.FormatAmount(l.Balance, currency)})
+	}
+	for _, child := range sec.Sections {
+		rows = append(rows, sectionRows(child, currency)...)
+	}
+	rows = append(rows, []string{sec.Prefix, sec.Name, "", "Total " + sec.Name, bookkeeping.FormatAmount(sec.Total, currency)})
+	return rows
+}
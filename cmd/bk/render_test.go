@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_render(t *testing.T) {
+	items := []bookkeeping.Account{
+		{Code: 1110, Name: "現金及び預金", IsBS: true, IsLeft: true},
+		{Code: 4100, Name: "商品売上高, 卸", IsBS: false, IsLeft: false, Inactive: true},
+	}
+	r := report{
+		data:  items,
+		text:  func(w io.Writer) { printAccounts(w, "", items) },
+		table: func() [][]string { return accountsTable(items) },
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"csv", "code,name,bs/pl,debit/credit,status\n1110,現金及び預金,BS,debit,active\n4100,\"商品売上高, 卸\",PL,credit,inactive\n"},
+		{"tsv", "code\tname\tbs/pl\tdebit/credit\tstatus\n1110\t現金及び預金\tBS\tdebit\tactive\n4100\t商品売上高, 卸\tPL\tcredit\tinactive\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := render(&globalOpts{format: tt.format, output: &buf}, r); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("render() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := render(&globalOpts{format: "json", output: &buf}, r); err != nil {
+			t.Fatal(err)
+		}
+		var got []map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[1]["code"] != float64(4100) || got[1]["inactive"] != true {
+			t.Errorf("render() = %s", buf.String())
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func reverseCmd() command {
+	fset := flag.NewFlagSet("bk reverse", flag.ExitOnError)
+	opts := &reverseOpts{date: today()}
+	fset.IntVar(&opts.id, "id", 0, "ID of the entry to reverse.")
+	fset.Var(&dateFlag{&opts.date}, "date", "Reversal post date. (format: yyyymmdd)")
+
+	return command{
+		name:        "reverse",
+		description: "Reverse posted entry",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return reverse(opts, glOpts)
+		},
+	}
+}
+
+type reverseOpts struct {
+	id   int
+	date time.Time
+}
+
+func reverse(opts *reverseOpts, glOpts *globalOpts) error {
+	if opts.id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+
+	id, err := bk.Reverse(opts.id, opts.date)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(glOpts.output, "entry %d posted, reversing entry %d\n", id, opts.id)
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func tbCmd() command {
+	fset := flag.NewFlagSet("bk tb", flag.ExitOnError)
+	opts := &tbOpts{}
+	fset.Var(&dateFlag{&opts.startDate}, "start", "start date of trial balance time period. (format: yyyymmdd)")
+	fset.Var(&dateFlag{&opts.endDate}, "end", "end date of trial balance time period. (format: yyyymmdd)")
+
+	return command{
+		name:        "tb",
+		description: "Show trial balance",
+		fset:        fset,
+		fn: func(args []string, glOpts *globalOpts) error {
+			fset.Parse(args)
+			return tb(opts, glOpts)
+		},
+	}
+}
+
+type tbOpts struct {
+	startDate time.Time
+	endDate   time.Time
+}
+
+func tb(opts *tbOpts, glOpts *globalOpts) error {
+
+	db, err := openBook(glOpts)
+	if err != nil {
+		return err
+	}
+	bk := bookkeeping.NewBookkeeping(db)
+	bk.SetLang(glOpts.locale)
+
+	fetchTBOpts := bookkeeping.FetchTrialBalanceOpts{
+		Start: opts.startDate,
+		End:   opts.endDate,
+	}
+
+	items, err := bk.FetchTrialBalance(fetchTBOpts)
+	if err != nil {
+		return err
+	}
+
+	err = render(glOpts, report{
+		data:  items,
+		text:  func(w io.Writer) { printTB(w, glOpts.locale, items) },
+		table: func() [][]string { return tbTable(items) },
+	})
+	if err != nil {
+		return err
+	}
+
+	return items.Check()
+}
+
+func tbTable(tb bookkeeping.TrialBalance) [][]string {
+	rows := [][]string{{"code", "name", "opening", "debit", "credit", "closing"}}
+	for _, l := range tb.Lines {
+		rows = append(rows, []string{
+			strconv.Itoa(l.Account.Code), l.Account.Name,
+			bookkeeping.FormatAmount(l.Opening, tb.Currency), bookkeeping.FormatAmount(l.Debit, tb.Currency), bookkeeping.FormatAmount(l.Credit, tb.Currency), bookkeeping.FormatAmount(l.Closing, tb.Currency),
+		})
+	}
+	rows = append(rows, []string{"", "Total", "", bookkeeping.FormatAmount(tb.TotalDebit, tb.Currency), bookkeeping.FormatAmount(tb.TotalCredit, tb.Currency), ""})
+	return rows
+}
+
+func printTB(w io.Writer, lang string, tb bookkeeping.TrialBalance) {
+	fmt.Fprintf(w, "%s (%s):\n", label(lang, "Trial Balance"), tb.Currency)
+	fmt.Fprintln(w)
+
+	fprintLFW(w, label(lang, "code"), 10)
+	fprintLFW(w, label(lang, "name"), 30)
+	fprintRFW(w, label(lang, "opening"), 15)
+	fprintRFW(w, label(lang, "debit"), 15)
+	fprintRFW(w, label(lang, "credit"), 15)
+	fprintRFW(w, label(lang, "closing"), 15)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 100))
+
+	for _, l := range tb.Lines {
+		fprintLFW(w, l.Account.Code, 10)
+		fprintLFW(w, l.Account.Name, 30)
+		fprintRFW(w, bookkeeping.FormatAmount(l.Opening, tb.Currency), 15)
+		fprintRFW(w, bookkeeping.FormatAmount(l.Debit, tb.Currency), 15)
+		fprintRFW(w, bookkeeping.FormatAmount(l.Credit, tb.Currency), 15)
+		fprintRFW(w, bookkeeping.FormatAmount(l.Closing, tb.Currency), 15)
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 100))
+	fprintLFW(w, label(lang, "Total"), 55)
+	fprintRFW(w, bookkeeping.FormatAmount(tb.TotalDebit, tb.Currency), 15)
+	fprintRFW(w, bookkeeping.FormatAmount(tb.TotalCredit, tb.Currency), 15)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+
+	fprintLFW(w, "", 40)
+	fprintRFW(w, label(lang, "debit"), 15)
+	fprintRFW(w, label(lang, "credit"), 15)
+	fmt.Fprintln(w)
+	fprintLFW(w, label(lang, "Opening Balance"), 40)
+	fprintRFW(w, bookkeeping.FormatAmount(tb.OpeningDebit, tb.Currency), 15)
+	fprintRFW(w, bookkeeping.FormatAmount(tb.OpeningCredit, tb.Currency), 15)
+	fmt.Fprintln(w)
+	fprintLFW(w, label(lang, "Closing Balance"), 40)
+	fprintRFW(w, bookkeeping.FormatAmount(tb.ClosingDebit, tb.Currency), 15)
+	fprintRFW(w, bookkeeping.FormatAmount(tb.ClosingCredit, tb.Currency), 15)
+	fmt.Fprintln(w)
+
+	if err := tb.Check(); err != nil {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, label(lang, "IMBALANCE DETECTED"))
+	}
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/mattn/go-runewidth"
+	"github.com/yoskeoka/bookkeeping"
 )
 
 func fprintLFW(o io.Writer, v interface{}, width int) {
@@ -14,3 +16,28 @@ func fprintLFW(o io.Writer, v interface{}, width int) {
 func fprintRFW(o io.Writer, v interface{}, width int) {
 	fmt.Fprint(o, runewidth.FillLeft(fmt.Sprintf("%v", v), width))
 }
+
+// printSection prints the account lines and subsections of a detailed report section with its total.
+func printSection(w io.Writer, lang string, sec bookkeeping.Section, currency string, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	fmt.Fprintln(w, indent+sec.Name)
+
+	for _, l := range sec.Lines {
+		name := l.Account.Name
+		if l.Account.Code > 0 {
+			name = fmt.Sprintf("%d %s", l.Account.Code, l.Account.Name)
+		}
+		fprintLFW(w, indent+"  "+name, 45)
+		fprintRFW(w, bookkeeping.FormatAmount(l.Balance, currency), 20)
+		fmt.Fprintln(w)
+	}
+
+	for _, child := range sec.Sections {
+		printSection(w, lang, child, currency, depth+1)
+	}
+
+	fprintLFW(w, indent+fmt.Sprintf(label(lang, "Total %s"), sec.Name), 45)
+	fprintRFW(w, bookkeeping.FormatAmount(sec.Total, currency), 20)
+	fmt.Fprintln(w)
+}
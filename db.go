@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -16,90 +18,240 @@ import (
 var sqlFiles embed.FS
 
 type DB struct {
+	// dbFilePath is empty for an in-memory database.
 	dbFilePath string
 	dbConn     *sql.DB
 }
 
-func NewDB(path string) (*DB, error) {
-	dir := filepath.Dir(path)
-	_, statDirErr := os.Stat(dir)
-	if errors.Is(statDirErr, os.ErrNotExist) {
-		err := os.MkdirAll(dir, 0744)
-		if err != nil {
-			return nil, err
+// dsnFile returns the database file path of the SQLite DSN, which is a file path or a "file:" URI
+// with optional query parameters, or ":memory:", and whether the database is in memory.
+func dsnFile(dsn string) (string, bool) {
+	if dsn == ":memory:" {
+		return "", true
+	}
+	if !strings.HasPrefix(dsn, "file:") {
+		if i := strings.Index(dsn, "?"); i >= 0 {
+			return dsn[:i], false
 		}
+		return dsn, false
 	}
 
-	var initRequired = false
-
-	_, dbStatErr := os.Stat(path)
-	if errors.Is(dbStatErr, os.ErrNotExist) {
-		initRequired = true
+	path, query := strings.TrimPrefix(dsn, "file:"), ""
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+	if path == ":memory:" || strings.Contains("&"+query+"&", "&mode=memory&") {
+		return "", true
 	}
+	return path, false
+}
 
-	sqlDB, err := sql.Open("sqlite", path)
+func openDSN(dsn string, memory bool) (*sql.DB, error) {
+	sqlDB, err := sql.Open("sqlite", dsn)
 	if err != nil {
+		return nil, fmt.Errorf("cannot open database %s: %v", dsn, err)
+	}
+	if memory {
+		// every connection to an in-memory database opens another database
+		sqlDB.SetMaxOpenConns(1)
+	}
+	return sqlDB, nil
+}
+
+// OpenDB opens the existing database without applying migrations.
+// dsn is a SQLite DSN, which is a file path or a "file:" URI.
+func OpenDB(dsn string) (*DB, error) {
+	path, memory := dsnFile(dsn)
+	if memory {
+		return nil, fmt.Errorf("cannot open existing in-memory database %s", dsn)
+	}
+	if _, err := os.Stat(path); err != nil {
 		return nil, fmt.Errorf("cannot open database file %s: %v", path, err)
 	}
 
+	sqlDB, err := openDSN(dsn, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{dbFilePath: path, dbConn: sqlDB}, nil
+}
+
+// NewDB opens the database, and applies the pending migrations.
+// dsn is a SQLite DSN, which is a file path, a "file:" URI or ":memory:".
+// If the database file does not exist or the database is in memory, it creates the database with the default accounts.
+func NewDB(dsn string) (*DB, error) {
+	return newDB(dsn, DefaultAccountTemplate)
+}
+
+// CreateDB creates a new database with the chart of accounts of the template.
+// It returns an error if the database file already exists.
+func CreateDB(dsn string, template string) (*DB, error) {
+	if _, err := findAccountTemplate(template); err != nil {
+		return nil, err
+	}
+
+	if path, memory := dsnFile(dsn); !memory {
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("database already exists: %s", path)
+		}
+	}
+
+	return newDB(dsn, template)
+}
+
+func newDB(dsn string, template string) (*DB, error) {
+	path, memory := dsnFile(dsn)
+
+	var initRequired = memory
+
+	if !memory {
+		dir := filepath.Dir(path)
+		_, statDirErr := os.Stat(dir)
+		if errors.Is(statDirErr, os.ErrNotExist) {
+			err := os.MkdirAll(dir, 0744)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		_, dbStatErr := os.Stat(path)
+		if errors.Is(dbStatErr, os.ErrNotExist) {
+			initRequired = true
+		}
+	}
+
+	sqlDB, err := openDSN(dsn, memory)
+	if err != nil {
+		return nil, err
+	}
+
 	db := &DB{
 		dbFilePath: path,
 		dbConn:     sqlDB,
 	}
 
+	// a database with applied migrations is never initialized again, which would delete its accounts
+	if initRequired {
+		v, err := db.SchemaVersion()
+		if err != nil {
+			return nil, err
+		}
+		initRequired = v == 0
+	}
+
+	// progress is reported to stderr, so that it never mixes with the output of commands in stdout
 	if initRequired {
-		fmt.Println("initializing database...")
-		if err := db.InitSchema(); err != nil {
+		fmt.Fprintln(os.Stderr, "initializing database...")
+		if _, err := db.Migrate(); err != nil {
 			return nil, fmt.Errorf("database init schema error: %v", err)
 		}
 
-		if err := db.InitAccounts(); err != nil {
+		if err := db.InitAccountsTemplate(template); err != nil {
 			return nil, fmt.Errorf("database init accounts data error: %v", err)
 		}
-		fmt.Println("database initialized:", path)
+		fmt.Fprintln(os.Stderr, "database initialized:", dsn)
+		return db, nil
+	}
+
+	applied, err := db.Migrate()
+	if err != nil {
+		return nil, fmt.Errorf("database migration error: %v", err)
+	}
+	if len(applied) > 0 {
+		fmt.Fprintf(os.Stderr, "database migrated to version %d: %s\n", applied[len(applied)-1].Version, dsn)
 	}
 
 	return db, nil
 }
 
+// InitSchema drops all of the tables, then creates them by applying all of the migrations.
 func (d *DB) InitSchema() error {
-	sb, err := sqlFiles.ReadFile("_embed/sql/schema.sql")
-	if err != nil {
+	if err := d.dropTables(); err != nil {
 		return err
 	}
 
-	_, err = d.dbConn.Exec(string(sb))
-	if err != nil {
-		return err
-	}
+	_, err := d.Migrate()
+	return err
+}
 
-	return nil
+// AccountTemplate is a chart of accounts to initialize a database with.
+type AccountTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Lang is the language of account names. Names in the other languages are stored as localized names.
+	Lang string `json:"lang"`
+}
+
+// DefaultAccountTemplate is the chart of accounts of a new database.
+const DefaultAccountTemplate = "ja"
+
+var AccountTemplates = []AccountTemplate{
+	{Name: "ja", Description: "Japanese small business", Lang: "ja"},
+	{Name: "en", Description: "English generic", Lang: "en"},
+	{Name: "en-us-gaap", Description: "English, US GAAP style", Lang: "en"},
+}
+
+func findAccountTemplate(name string) (AccountTemplate, error) {
+	for _, t := range AccountTemplates {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return AccountTemplate{}, fmt.Errorf("unknown account template '%s'", name)
 }
 
+// InitAccounts replaces all of the accounts with the default chart of accounts.
 func (d *DB) InitAccounts() error {
-	acc, err := sqlFiles.ReadFile("_embed/sql/accounts_ja.sql")
+	return d.InitAccountsTemplate(DefaultAccountTemplate)
+}
+
+// InitAccountsTemplate replaces all of the accounts with the chart of accounts of the template.
+func (d *DB) InitAccountsTemplate(name string) error {
+	t, err := findAccountTemplate(name)
 	if err != nil {
 		return err
 	}
 
-	_, err = d.dbConn.Exec("delete from accounts")
+	acc, err := sqlFiles.ReadFile("_embed/sql/accounts_" + strings.ReplaceAll(t.Name, "-", "_") + ".sql")
 	if err != nil {
 		return err
 	}
 
-	_, err = d.dbConn.Exec(string(acc))
+	tx, err := d.dbConn.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	for _, q := range []string{"delete from account_names", "delete from accounts", string(acc)} {
+		if _, err := tx.Exec(q); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("insert or replace into settings(key, value) values(?, ?)", settingAccountsLang, t.Lang); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (d *DB) Close() error {
 	return d.dbConn.Close()
 }
 
+// Delete closes the database, then deletes the database file.
+// The file is never removed while the database is open.
 func (d *DB) Delete() error {
+	if d.dbFilePath == "" {
+		return fmt.Errorf("cannot delete in-memory database")
+	}
+
+	if err := d.Close(); err != nil {
+		return fmt.Errorf("cannot close database: %v", err)
+	}
+
 	err := os.Remove(d.dbFilePath)
 	if err != nil {
 		return fmt.Errorf("cannot delete database: %v", err)
@@ -107,6 +259,54 @@ func (d *DB) Delete() error {
 	return nil
 }
 
+// Backup writes a copy of the database into the file by VACUUM INTO, which is consistent while the database is in use.
+// The file must not exist.
+func (d *DB) Backup(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup file %s already exists", path)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0744); err != nil {
+		return err
+	}
+
+	if _, err := d.dbConn.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("cannot backup database into %s: %v", path, err)
+	}
+	return nil
+}
+
+// RestoreDB replaces the database file at path with the backup file.
+// The database at path must be closed while restoring.
+func RestoreDB(backupPath, path string) error {
+	backup, err := OpenDB(backupPath)
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	var n int
+	err = backup.dbConn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('accounts', 'journals')").Scan(&n)
+	if err != nil {
+		return fmt.Errorf("cannot read backup file %s: %v", backupPath, err)
+	}
+	if n != 2 {
+		return fmt.Errorf("backup file %s is not a bookkeeping database", backupPath)
+	}
+
+	// write into a temporary file first, so that the database is kept as is on failure
+	tmp := fmt.Sprintf("%s.restore-%d", path, time.Now().UnixNano())
+	if err := backup.Backup(tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("cannot restore database %s: %v", path, err)
+	}
+	return nil
+}
+
 type DBAccounts struct {
 	db *DB
 }
@@ -122,14 +322,14 @@ func (a *DBAccounts) Insert(items ...Account) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare("insert into accounts(code, name, is_bs, is_left) values(?, ?, ?, ?)")
+	stmt, err := tx.Prepare("insert into accounts(code, name, is_bs, is_left, inactive) values(?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	for _, item := range items {
-		_, err := stmt.Exec(item.Code, item.Name, item.IsBS, item.IsLeft)
+		_, err := stmt.Exec(item.Code, item.Name, item.IsBS, item.IsLeft, item.Inactive)
 		if err != nil {
 			return err
 		}
@@ -138,36 +338,86 @@ func (a *DBAccounts) Insert(items ...Account) error {
 	return tx.Commit()
 }
 
+// Update updates the name and the active state of the account of item.Code.
+func (a *DBAccounts) Update(item Account) error {
+	res, err := a.db.dbConn.Exec("update accounts set name = ?, inactive = ? where code = ?", item.Name, item.Inactive, item.Code)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("account code '%d' is not found", item.Code)
+	}
+	return nil
+}
+
+// SetName sets the name of the account in the language, which is shown instead of the name as added.
+func (a *DBAccounts) SetName(code int, lang string, name string) error {
+	_, err := a.db.dbConn.Exec("insert or replace into account_names(code, lang, name) values(?, ?, ?)", code, lang, name)
+	return err
+}
+
+// FetchNames returns the localized names of accounts by account code and language.
+func (a *DBAccounts) FetchNames() (map[int]map[string]string, error) {
+	rows, err := a.db.dbConn.Query("SELECT code, lang, name FROM account_names ORDER BY code, lang")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[int]map[string]string)
+	for rows.Next() {
+		var code int
+		var lang, name string
+		if err := rows.Scan(&code, &lang, &name); err != nil {
+			return nil, err
+		}
+		if names[code] == nil {
+			names[code] = make(map[string]string)
+		}
+		names[code][lang] = name
+	}
+	return names, rows.Err()
+}
+
 type DBAccountsFetchOption struct {
 	CodePattern        string
 	DescriptionPattern string
+	// Lang is the language of account names. Accounts without the localized name have the name as added.
+	Lang string
 }
 
 func (a *DBAccounts) Fetch(opt DBAccountsFetchOption) ([]Account, error) {
 	q := []string{
 		`
-		SELECT *
-		FROM accounts
+		SELECT ac.code, COALESCE(an.name, ac.name), ac.is_bs, ac.is_left, ac.inactive
+		FROM accounts AS ac
+		LEFT JOIN account_names AS an ON an.code = ac.code AND an.lang = ?
 		`,
 	}
 	w := []string{}
-	args := []interface{}{}
+	args := []interface{}{opt.Lang}
 
 	if opt.CodePattern != "" {
-		w = append(w, "code LIKE ?")
+		w = append(w, "ac.code LIKE ?")
 		p := strings.ReplaceAll(opt.CodePattern, "*", "%")
 		args = append(args, p)
 	}
 
 	if opt.DescriptionPattern != "" {
-		w = append(w, "name LIKE ?")
+		w = append(w, "COALESCE(an.name, ac.name) LIKE ?")
 		p := strings.ReplaceAll(opt.DescriptionPattern, "*", "%")
 		args = append(args, p)
 	}
 
 	if len(w) > 0 {
-		q = append(q, "WHERE", strings.Join(w, " AND"))
+		q = append(q, "WHERE", strings.Join(w, " AND "))
 	}
+	q = append(q, "ORDER BY ac.code")
 
 	query := strings.Join(q, " ")
 	stmt, err := a.db.dbConn.Prepare(query)
@@ -184,7 +434,7 @@ func (a *DBAccounts) Fetch(opt DBAccountsFetchOption) ([]Account, error) {
 	for rows.Next() {
 		item := Account{}
 		err := rows.Scan(
-			&item.Code, &item.Name, &item.IsBS, &item.IsLeft,
+			&item.Code, &item.Name, &item.IsBS, &item.IsLeft, &item.Inactive,
 		)
 		if err != nil {
 			return nil, err
@@ -194,6 +444,150 @@ func (a *DBAccounts) Fetch(opt DBAccountsFetchOption) ([]Account, error) {
 	return items, nil
 }
 
+type DBEntries struct {
+	db *DB
+}
+
+func NewDBEntries(db *DB) *DBEntries {
+	return &DBEntries{db}
+}
+
+// Insert inserts each entry header with its journals, and returns the IDs of the inserted entries.
+// All entries are inserted in a single database transaction.
+func (e *DBEntries) Insert(items ...Entry) ([]int, error) {
+	tx, err := e.db.dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		id, err := insertEntry(tx, item)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func insertEntry(tx *sql.Tx, item Entry) (int, error) {
+	date := item.Date
+	if !date.Valid && len(item.Journals) > 0 {
+		date = item.Journals[0].Date
+	}
+
+	reverses := sql.NullInt64{Int64: int64(item.ReversalOf), Valid: item.ReversalOf > 0}
+
+	res, err := tx.Exec("insert into transactions(date, memo, reverses_id, closing, created_at) values(?, ?, ?, ?, ?)",
+		date, item.Memo, reverses, item.Closing, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare("insert into journals(transaction_id, code, date, description, currency, left, right, func_left, func_right) values(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, j := range item.Journals {
+		currency := j.Currency
+		if currency == "" {
+			currency = DefaultCurrency
+		}
+		// journals in the functional currency need no conversion
+		if currency == DefaultCurrency && j.FuncLeft == 0 && j.FuncRight == 0 {
+			j.FuncLeft, j.FuncRight = j.Left, j.Right
+		}
+		_, err := stmt.Exec(id, j.Code, j.Date, j.Description, currency, j.Left, j.Right, j.FuncLeft, j.FuncRight)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int(id), nil
+}
+
+type DBEntriesFetchOption struct {
+	ID []int
+	// Lang is the language of account names of journals.
+	Lang string
+}
+
+// Fetch returns entries with their journals, ordered by entry ID.
+func (e *DBEntries) Fetch(opt DBEntriesFetchOption) ([]Entry, error) {
+	q := []string{
+		`
+		SELECT t.id, t.date, t.memo, t.closing, t.created_at,
+				COALESCE(t.reverses_id, 0),
+				COALESCE((SELECT r.id FROM transactions AS r WHERE r.reverses_id = t.id), 0)
+		FROM transactions AS t
+		`,
+	}
+	args := []interface{}{}
+
+	if len(opt.ID) > 0 {
+		q = append(q, "WHERE t.id IN ("+strings.Repeat("?,", len(opt.ID)-1)+"?)")
+		for _, id := range opt.ID {
+			args = append(args, id)
+		}
+	}
+	q = append(q, "ORDER BY t.id")
+
+	query := strings.Join(q, " ")
+	stmt, err := e.db.dbConn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []Entry{}
+	ids := []int{}
+	for rows.Next() {
+		item := Entry{}
+		err := rows.Scan(&item.ID, &item.Date, &item.Memo, &item.Closing, &item.CreatedAt, &item.ReversalOf, &item.ReversedBy)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		ids = append(ids, item.ID)
+	}
+
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	journals, err := NewDBJournals(e.db).Fetch(DBJournalsFetchOption{EntryID: ids, Lang: opt.Lang})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(journals, func(i, j int) bool { return journals[i].ID < journals[j].ID })
+
+	idx := make(map[int]int, len(items))
+	for i, item := range items {
+		idx[item.ID] = i
+	}
+	for _, j := range journals {
+		i := idx[j.EntryID]
+		items[i].Journals = append(items[i].Journals, j)
+	}
+	return items, nil
+}
+
 type DBJournals struct {
 	db *DB
 }
@@ -202,6 +596,7 @@ func NewDBJournals(db *DB) *DBJournals {
 	return &DBJournals{db}
 }
 
+// Insert inserts items as journals of a single entry.
 func (jn *DBJournals) Insert(items ...Journal) error {
 	tx, err := jn.db.dbConn.Begin()
 	if err != nil {
@@ -209,26 +604,31 @@ func (jn *DBJournals) Insert(items ...Journal) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare("insert into journals(code, date, description, left, right) values(?, ?, ?, ?, ?)")
-	if err != nil {
+	if _, err := insertEntry(tx, Entry{Journals: items}); err != nil {
 		return err
 	}
-	defer stmt.Close()
-
-	for _, item := range items {
-		_, err := stmt.Exec(item.Code, item.Date, item.Description, item.Left, item.Right)
-		if err != nil {
-			return err
-		}
-	}
 
 	return tx.Commit()
 }
 
 type DBJournalsFetchOption struct {
-	After  sql.NullTime
-	Before sql.NullTime
-	Code   []int
+	After   sql.NullTime
+	Before  sql.NullTime
+	Code    []int
+	EntryID []int
+
+	Currency           string
+	DescriptionPattern string
+	// MinAmount and MaxAmount filter by the amount of debit or credit in Currency,
+	// or by the functional currency amount if Currency is empty.
+	MinAmount sql.NullInt64
+	MaxAmount sql.NullInt64
+	// ExcludeClosing excludes journals of year-end closing entries.
+	ExcludeClosing bool
+	// Uncleared fetches only the journals not reconciled yet.
+	Uncleared bool
+	// Lang is the language of account names.
+	Lang string
 
 	// this may conflict with Code
 	CodeRangeFrom int
@@ -245,14 +645,19 @@ func (opt DBJournalsFetchOption) CodeRange(from, to int) DBJournalsFetchOption {
 func (jn *DBJournals) Fetch(opt DBJournalsFetchOption) ([]Journal, error) {
 	q := []string{
 		`
-		SELECT jn.id, jn.date, jn.code, jn.description, jn.left, jn.right, 
-				a.code, a.name, a.is_bs, a.is_left
+		SELECT jn.id, jn.transaction_id, jn.date, jn.code, jn.description, jn.currency, jn.left, jn.right, jn.func_left, jn.func_right,
+				COALESCE(t.reverses_id, 0),
+				COALESCE((SELECT r.id FROM transactions AS r WHERE r.reverses_id = jn.transaction_id), 0),
+				COALESCE(jn.reconciled, FALSE), COALESCE(jn.statement_ref, ''),
+				a.code, COALESCE(an.name, a.name), a.is_bs, a.is_left, a.inactive
 		FROM journals AS jn
 		INNER JOIN accounts AS a ON a.code = jn.code
+		LEFT JOIN account_names AS an ON an.code = a.code AND an.lang = ?
+		LEFT JOIN transactions AS t ON t.id = jn.transaction_id
 		`,
 	}
 	w := []string{}
-	args := []interface{}{}
+	args := []interface{}{opt.Lang}
 
 	if opt.After.Valid {
 		w = append(w, "? <= jn.date")
@@ -268,6 +673,39 @@ func (jn *DBJournals) Fetch(opt DBJournalsFetchOption) ([]Journal, error) {
 			args = append(args, c)
 		}
 	}
+	if len(opt.EntryID) > 0 {
+		w = append(w, "jn.transaction_id IN ("+strings.Repeat("?,", len(opt.EntryID)-1)+"?)")
+		for _, id := range opt.EntryID {
+			args = append(args, id)
+		}
+	}
+	if opt.Currency != "" {
+		w = append(w, "jn.currency = ?")
+		args = append(args, opt.Currency)
+	}
+	if opt.DescriptionPattern != "" {
+		w = append(w, "jn.description LIKE ?")
+		p := strings.ReplaceAll(opt.DescriptionPattern, "*", "%")
+		args = append(args, p)
+	}
+	amount := "jn.left + jn.right"
+	if opt.Currency == "" {
+		amount = "jn.func_left + jn.func_right"
+	}
+	if opt.MinAmount.Valid {
+		w = append(w, "? <= "+amount)
+		args = append(args, opt.MinAmount)
+	}
+	if opt.MaxAmount.Valid {
+		w = append(w, "? >= "+amount)
+		args = append(args, opt.MaxAmount)
+	}
+	if opt.ExcludeClosing {
+		w = append(w, "COALESCE(t.closing, FALSE) = FALSE")
+	}
+	if opt.Uncleared {
+		w = append(w, "COALESCE(jn.reconciled, FALSE) = FALSE")
+	}
 	if opt.CodeRangeFrom > 0 {
 		w = append(w, "? <= jn.code")
 		args = append(args, opt.CodeRangeFrom)
@@ -278,7 +716,7 @@ func (jn *DBJournals) Fetch(opt DBJournalsFetchOption) ([]Journal, error) {
 	}
 
 	if len(w) > 0 {
-		q = append(q, "WHERE", strings.Join(w, " AND"))
+		q = append(q, "WHERE", strings.Join(w, " AND "))
 	}
 
 	query := strings.Join(q, " ")
@@ -296,8 +734,10 @@ func (jn *DBJournals) Fetch(opt DBJournalsFetchOption) ([]Journal, error) {
 	for rows.Next() {
 		item := Journal{}
 		err := rows.Scan(
-			&item.ID, &item.Date, &item.Code, &item.Description, &item.Left, &item.Right,
-			&item.Account.Code, &item.Account.Name, &item.Account.IsBS, &item.Account.IsLeft,
+			&item.ID, &item.EntryID, &item.Date, &item.Code, &item.Description, &item.Currency, &item.Left, &item.Right, &item.FuncLeft, &item.FuncRight,
+			&item.ReversalOf, &item.ReversedBy,
+			&item.Reconciled, &item.StatementRef,
+			&item.Account.Code, &item.Account.Name, &item.Account.IsBS, &item.Account.IsLeft, &item.Account.Inactive,
 		)
 		if err != nil {
 			return nil, err
@@ -306,3 +746,595 @@ func (jn *DBJournals) Fetch(opt DBJournalsFetchOption) ([]Journal, error) {
 	}
 	return items, nil
 }
+
+// Reconcile marks the journals of the IDs as reconciled on the statement in a single database transaction.
+// It fails if any of the journals is not found or already reconciled.
+func (jn *DBJournals) Reconcile(ids []int, statementRef string) error {
+	tx, err := jn.db.dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("update journals set reconciled = TRUE, statement_ref = ? where id = ? and not COALESCE(reconciled, FALSE)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		res, err := stmt.Exec(statementRef, id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("journal '%d' is not found or already reconciled", id)
+		}
+	}
+
+	return tx.Commit()
+}
+
+type DBExchangeRates struct {
+	db *DB
+}
+
+func NewDBExchangeRates(db *DB) *DBExchangeRates {
+	return &DBExchangeRates{db}
+}
+
+// Insert inserts rates, replacing the rate of the same currency pair and date.
+func (r *DBExchangeRates) Insert(items ...ExchangeRate) error {
+	tx, err := r.db.dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("insert or replace into exchange_rates(base, quote, date, rate) values(?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, item := range items {
+		_, err := stmt.Exec(item.Base, item.Quote, item.Date, item.Rate)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+type DBExchangeRatesFetchOption struct {
+	Base   string
+	Quote  string
+	Before sql.NullTime
+	// Latest fetches only the latest rate of each currency pair.
+	Latest bool
+}
+
+// Fetch returns rates ordered by currency pair, then by date.
+func (r *DBExchangeRates) Fetch(opt DBExchangeRatesFetchOption) ([]ExchangeRate, error) {
+	q := []string{
+		`
+		SELECT fx.base, fx.quote, fx.date, fx.rate
+		FROM exchange_rates AS fx
+		`,
+	}
+	w := []string{}
+	args := []interface{}{}
+
+	if opt.Base != "" {
+		w = append(w, "fx.base = ?")
+		args = append(args, opt.Base)
+	}
+	if opt.Quote != "" {
+		w = append(w, "fx.quote = ?")
+		args = append(args, opt.Quote)
+	}
+	if opt.Before.Valid {
+		w = append(w, "? >= fx.date")
+		args = append(args, opt.Before)
+	}
+	if opt.Latest {
+		latest := "fx.date = (SELECT MAX(l.date) FROM exchange_rates AS l WHERE l.base = fx.base AND l.quote = fx.quote"
+		if opt.Before.Valid {
+			latest += " AND ? >= l.date"
+			args = append(args, opt.Before)
+		}
+		w = append(w, latest+")")
+	}
+
+	if len(w) > 0 {
+		q = append(q, "WHERE", strings.Join(w, " AND "))
+	}
+	q = append(q, "ORDER BY fx.base, fx.quote, fx.date")
+
+	query := strings.Join(q, " ")
+	stmt, err := r.db.dbConn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []ExchangeRate{}
+	for rows.Next() {
+		item := ExchangeRate{}
+		err := rows.Scan(&item.Base, &item.Quote, &item.Date, &item.Rate)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+type DBSettings struct {
+	db *DB
+}
+
+func NewDBSettings(db *DB) *DBSettings {
+	return &DBSettings{db}
+}
+
+// Get returns the value of the setting key, or empty string if it is not set.
+func (s *DBSettings) Get(key string) (string, error) {
+	var v sql.NullString
+	err := s.db.dbConn.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return v.String, nil
+}
+
+// Set sets the value of the setting key.
+func (s *DBSettings) Set(key, value string) error {
+	_, err := s.db.dbConn.Exec("insert or replace into settings(key, value) values(?, ?)", key, value)
+	return err
+}
+
+// SetWithEntries inserts the entries and sets the value of the setting key in a single database transaction,
+// and returns the IDs of the inserted entries.
+func (s *DBSettings) SetWithEntries(key, value string, entries []Entry) ([]int, error) {
+	tx, err := s.db.dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		id, err := insertEntry(tx, e)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if _, err := tx.Exec("insert or replace into settings(key, value) values(?, ?)", key, value); err != nil {
+		return nil, err
+	}
+	return ids, tx.Commit()
+}
+
+type DBBankReviews struct {
+	db *DB
+}
+
+func NewDBBankReviews(db *DB) *DBBankReviews {
+	return &DBBankReviews{db}
+}
+
+// Insert inserts the entries, and the reviews of the entries of the same index in a single database transaction,
+// and returns the IDs of the inserted entries. A review with zero Code is not inserted.
+func (r *DBBankReviews) Insert(entries []Entry, reviews []BankReview) ([]int, error) {
+	if len(entries) != len(reviews) {
+		return nil, fmt.Errorf("%d entries and %d reviews must be the same number", len(entries), len(reviews))
+	}
+
+	tx, err := r.db.dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, 0, len(entries))
+	for i, e := range entries {
+		id, err := insertEntry(tx, e)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+
+		rv := reviews[i]
+		if rv.Code == 0 {
+			continue
+		}
+		_, err = tx.Exec("insert into bank_reviews(transaction_id, code, suspense, date, payee, amount) values(?, ?, ?, ?, ?, ?)",
+			id, rv.Code, rv.Suspense, rv.Date, rv.Payee, rv.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Resolve inserts the entry which resolves the review of the ID in a single database transaction,
+// and returns the ID of the inserted entry.
+func (r *DBBankReviews) Resolve(id int, e Entry) (int, error) {
+	tx, err := r.db.dbConn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	entryID, err := insertEntry(tx, e)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec("update bank_reviews set resolved_transaction_id = ? where id = ? and resolved_transaction_id is null", entryID, id)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("bank review '%d' is not found or already resolved", id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return entryID, nil
+}
+
+type DBBankReviewsFetchOption struct {
+	ID   []int
+	Code int
+	// EntryID fetches the reviews of the entry posted from the line, or the entry which resolved them.
+	EntryID int
+	// Unresolved fetches only the reviews not resolved yet.
+	Unresolved bool
+}
+
+// Fetch returns reviews ordered by date, then by ID.
+func (r *DBBankReviews) Fetch(opt DBBankReviewsFetchOption) ([]BankReview, error) {
+	q := []string{
+		`
+		SELECT id, transaction_id, code, suspense, date, payee, amount, COALESCE(resolved_transaction_id, 0)
+		FROM bank_reviews
+		`,
+	}
+	w := []string{}
+	args := []interface{}{}
+
+	if len(opt.ID) > 0 {
+		w = append(w, "id IN ("+strings.Repeat("?,", len(opt.ID)-1)+"?)")
+		for _, id := range opt.ID {
+			args = append(args, id)
+		}
+	}
+	if opt.Code > 0 {
+		w = append(w, "code = ?")
+		args = append(args, opt.Code)
+	}
+	if opt.EntryID > 0 {
+		w = append(w, "(transaction_id = ? OR resolved_transaction_id = ?)")
+		args = append(args, opt.EntryID, opt.EntryID)
+	}
+	if opt.Unresolved {
+		w = append(w, "resolved_transaction_id IS NULL")
+	}
+
+	if len(w) > 0 {
+		q = append(q, "WHERE", strings.Join(w, " AND "))
+	}
+	q = append(q, "ORDER BY date, id")
+
+	rows, err := r.db.dbConn.Query(strings.Join(q, " "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []BankReview{}
+	for rows.Next() {
+		item := BankReview{}
+		err := rows.Scan(&item.ID, &item.EntryID, &item.Code, &item.Suspense, &item.Date, &item.Payee, &item.Amount, &item.ResolvedBy)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+type DBCustomers struct {
+	db *DB
+}
+
+func NewDBCustomers(db *DB) *DBCustomers {
+	return &DBCustomers{db}
+}
+
+// Insert inserts customers in a single database transaction, and returns the IDs of the inserted customers.
+func (c *DBCustomers) Insert(items ...Customer) ([]int, error) {
+	tx, err := c.db.dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("insert into customers(name) values(?)")
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		res, err := stmt.Exec(item.Name)
+		if err != nil {
+			return nil, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, int(id))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+type DBCustomersFetchOption struct {
+	ID   []int
+	Name string
+}
+
+// Fetch returns customers ordered by ID.
+func (c *DBCustomers) Fetch(opt DBCustomersFetchOption) ([]Customer, error) {
+	q := []string{"SELECT id, name FROM customers"}
+	w := []string{}
+	args := []interface{}{}
+
+	if len(opt.ID) > 0 {
+		w = append(w, "id IN ("+strings.Repeat("?,", len(opt.ID)-1)+"?)")
+		for _, id := range opt.ID {
+			args = append(args, id)
+		}
+	}
+	if opt.Name != "" {
+		w = append(w, "name = ?")
+		args = append(args, opt.Name)
+	}
+
+	if len(w) > 0 {
+		q = append(q, "WHERE", strings.Join(w, " AND "))
+	}
+	q = append(q, "ORDER BY id")
+
+	rows, err := c.db.dbConn.Query(strings.Join(q, " "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []Customer{}
+	for rows.Next() {
+		item := Customer{}
+		if err := rows.Scan(&item.ID, &item.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+type DBInvoices struct {
+	db *DB
+}
+
+func NewDBInvoices(db *DB) *DBInvoices {
+	return &DBInvoices{db}
+}
+
+// Insert inserts the entry and the invoice of the entry in a single database transaction,
+// and returns the ID of the inserted invoice.
+func (in *DBInvoices) Insert(inv Invoice, e Entry) (int, error) {
+	tx, err := in.db.dbConn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	entryID, err := insertEntry(tx, e)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec("insert into invoices(customer_id, transaction_id, date, amount, memo) values(?, ?, ?, ?, ?)",
+		inv.CustomerID, entryID, inv.Date, inv.Amount, inv.Memo)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// InsertPayments inserts the entry and the payments of the entry in a single database transaction,
+// and returns the ID of the inserted entry.
+func (in *DBInvoices) InsertPayments(e Entry, payments []InvoicePayment) (int, error) {
+	tx, err := in.db.dbConn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	entryID, err := insertEntry(tx, e)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare("insert into invoice_payments(invoice_id, transaction_id, date, amount) values(?, ?, ?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, p := range payments {
+		if _, err := stmt.Exec(p.InvoiceID, entryID, p.Date, p.Amount); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return entryID, nil
+}
+
+type DBInvoicesFetchOption struct {
+	ID         []int
+	CustomerID int
+	// EntryID fetches the invoice posted as the entry.
+	EntryID int
+	// Before fetches invoices dated on or before the date, with the payments dated on or before the date.
+	Before sql.NullTime
+}
+
+// Fetch returns invoices ordered by date, then by ID.
+func (in *DBInvoices) Fetch(opt DBInvoicesFetchOption) ([]Invoice, error) {
+	paid := "SELECT SUM(p.amount) FROM invoice_payments AS p WHERE p.invoice_id = inv.id"
+	args := []interface{}{}
+	if opt.Before.Valid {
+		paid += " AND p.date <= ?"
+		args = append(args, opt.Before)
+	}
+
+	q := []string{
+		`
+		SELECT inv.id, inv.customer_id, c.name, inv.date, inv.amount, inv.memo, inv.transaction_id,
+				COALESCE((` + paid + `), 0)
+		FROM invoices AS inv
+		INNER JOIN customers AS c ON c.id = inv.customer_id
+		`,
+	}
+	w := []string{}
+
+	if len(opt.ID) > 0 {
+		w = append(w, "inv.id IN ("+strings.Repeat("?,", len(opt.ID)-1)+"?)")
+		for _, id := range opt.ID {
+			args = append(args, id)
+		}
+	}
+	if opt.CustomerID > 0 {
+		w = append(w, "inv.customer_id = ?")
+		args = append(args, opt.CustomerID)
+	}
+	if opt.EntryID > 0 {
+		w = append(w, "inv.transaction_id = ?")
+		args = append(args, opt.EntryID)
+	}
+	if opt.Before.Valid {
+		w = append(w, "inv.date <= ?")
+		args = append(args, opt.Before)
+	}
+
+	if len(w) > 0 {
+		q = append(q, "WHERE", strings.Join(w, " AND "))
+	}
+	q = append(q, "ORDER BY inv.date, inv.id")
+
+	rows, err := in.db.dbConn.Query(strings.Join(q, " "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []Invoice{}
+	for rows.Next() {
+		item := Invoice{}
+		err := rows.Scan(&item.ID, &item.CustomerID, &item.CustomerName, &item.Date, &item.Amount, &item.Memo, &item.EntryID, &item.Paid)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+type DBInvoicePaymentsFetchOption struct {
+	InvoiceID []int
+	// EntryID fetches the payments posted as the entry.
+	EntryID int
+}
+
+// FetchPayments returns payments applied to invoices ordered by date, then by ID.
+func (in *DBInvoices) FetchPayments(opt DBInvoicePaymentsFetchOption) ([]InvoicePayment, error) {
+	q := []string{"SELECT id, invoice_id, transaction_id, date, amount FROM invoice_payments"}
+	w := []string{}
+	args := []interface{}{}
+
+	if len(opt.InvoiceID) > 0 {
+		w = append(w, "invoice_id IN ("+strings.Repeat("?,", len(opt.InvoiceID)-1)+"?)")
+		for _, id := range opt.InvoiceID {
+			args = append(args, id)
+		}
+	}
+	if opt.EntryID > 0 {
+		w = append(w, "transaction_id = ?")
+		args = append(args, opt.EntryID)
+	}
+
+	if len(w) > 0 {
+		q = append(q, "WHERE", strings.Join(w, " AND "))
+	}
+	q = append(q, "ORDER BY date, id")
+
+	rows, err := in.db.dbConn.Query(strings.Join(q, " "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []InvoicePayment{}
+	for rows.Next() {
+		item := InvoicePayment{}
+		if err := rows.Scan(&item.ID, &item.InvoiceID, &item.EntryID, &item.Date, &item.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
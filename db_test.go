@@ -2,6 +2,7 @@ package bookkeeping_test
 
 import (
 	"database/sql"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -12,11 +13,8 @@ import (
 
 func NewTestDB(t *testing.T) *bookkeeping.DB {
 	t.Helper()
-	tmpDir := t.TempDir()
-	f := filepath.Join(tmpDir, "bookkeeping_test.db")
-	t.Logf("test db: %v", f)
 
-	tdb, err := bookkeeping.NewDB(f)
+	tdb, err := bookkeeping.NewDB(":memory:")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -28,7 +26,6 @@ func NewTestDB(t *testing.T) *bookkeeping.DB {
 
 	t.Cleanup(func() {
 		tdb.Close()
-		tdb.Delete()
 	})
 
 	return tdb
@@ -49,6 +46,7 @@ func initAccounts(t *testing.T, tdb *bookkeeping.DB) {
 		{Code: 2200, Name: "長期借入金", IsBS: true, IsLeft: false},
 		{Code: 3100, Name: "資本金", IsBS: true, IsLeft: false},
 		{Code: 3200, Name: "資本剰余金", IsBS: true, IsLeft: false},
+		{Code: 3300, Name: "繰越利益剰余金", IsBS: true, IsLeft: false},
 		{Code: 4100, Name: "商品売上高", IsBS: false, IsLeft: false},
 		{Code: 5100, Name: "期首商品棚卸高", IsBS: false, IsLeft: true},
 		{Code: 5200, Name: "商品仕入高", IsBS: false, IsLeft: true},
@@ -110,6 +108,34 @@ func Test_DBJournals_Insert(t *testing.T) {
 	}
 }
 
+func Test_DBSettings_SetWithEntries(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+
+	st := bookkeeping.NewDBSettings(tdb)
+	ids, err := st.SetWithEntries("closed_through", "2021-03-31", []bookkeeping.Entry{{
+		Date: date(2021, 3, 31),
+		Journals: []bookkeeping.Journal{
+			{Date: date(2021, 3, 31), Code: 1110, Left: 1000},
+			{Date: date(2021, 3, 31), Code: 3100, Right: 1000},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := bookkeeping.NewDBEntries(tdb).Fetch(bookkeeping.DBEntriesFetchOption{ID: ids})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || len(entries) != 1 || len(entries[0].Journals) != 2 {
+		t.Errorf("DBSettings.SetWithEntries() must insert the entry, but got %v %+v", ids, entries)
+	}
+	if v, err := st.Get("closed_through"); err != nil || v != "2021-03-31" {
+		t.Errorf("DBSettings.SetWithEntries() must set the value, but got %v %v", v, err)
+	}
+}
+
 func date(year int, month time.Month, day int) sql.NullTime {
 	return sql.NullTime{
 		Time:  time.Date(year, month, day, 0, 0, 0, 0, time.UTC),
@@ -192,6 +218,31 @@ func Test_DBJournals_Fetch(t *testing.T) {
 				{Date: date(2021, 01, 03), Code: 3100, Description: "資本金", Left: 0, Right: 100000},
 			},
 		},
+		{
+			"DescriptionPattern",
+			args{bookkeeping.DBJournalsFetchOption{DescriptionPattern: "*本*"}},
+			[]bookkeeping.Journal{
+				{Date: date(2021, 01, 03), Code: 1110, Description: "現金及び預金", Left: 100000, Right: 0},
+				{Date: date(2021, 01, 03), Code: 3100, Description: "資本金", Left: 0, Right: 100000},
+			},
+			[]bookkeeping.Journal{
+				{Date: date(2021, 01, 03), Code: 3100, Description: "資本金", Left: 0, Right: 100000},
+			},
+		},
+		{
+			"MinAmount and MaxAmount",
+			args{bookkeeping.DBJournalsFetchOption{MinAmount: sql.NullInt64{Int64: 20000, Valid: true}, MaxAmount: sql.NullInt64{Int64: 50000, Valid: true}}},
+			[]bookkeeping.Journal{
+				{Date: date(2021, 01, 03), Code: 1110, Description: "現金及び預金", Left: 100000, Right: 0},
+				{Date: date(2021, 01, 03), Code: 3100, Description: "資本金", Left: 0, Right: 100000},
+				{Date: date(2021, 01, 16), Code: 1110, Description: "現金及び預金", Left: 20000, Right: 0},
+				{Date: date(2021, 01, 16), Code: 3100, Description: "資本金", Left: 0, Right: 20000},
+			},
+			[]bookkeeping.Journal{
+				{Date: date(2021, 01, 16), Code: 1110, Description: "現金及び預金", Left: 20000, Right: 0},
+				{Date: date(2021, 01, 16), Code: 3100, Description: "資本金", Left: 0, Right: 20000},
+			},
+		},
 		{
 			"CodeRangeTo",
 			args{bookkeeping.DBJournalsFetchOption{CodeRangeTo: 3000}},
@@ -243,3 +294,175 @@ func Test_DBJournals_Fetch(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewDB_DSN(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "bookkeeping_test.db")
+
+	for _, dsn := range []string{":memory:", "file::memory:", "file:" + f} {
+		tdb, err := bookkeeping.NewDB(dsn)
+		if err != nil {
+			t.Fatalf("NewDB(%s) error = %v", dsn, err)
+		}
+
+		accs, err := bookkeeping.NewDBAccounts(tdb).Fetch(bookkeeping.DBAccountsFetchOption{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(accs) == 0 {
+			t.Errorf("NewDB(%s) must initialize accounts", dsn)
+		}
+		tdb.Close()
+	}
+
+	if _, err := os.Stat(f); err != nil {
+		t.Errorf("NewDB() with file URI must create the database file: %v", err)
+	}
+}
+
+func Test_NewDB_DSNQuery(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "bookkeeping_test.db")
+
+	tdb, err := bookkeeping.NewDB(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bookkeeping.NewBookkeeping(tdb).AddAccount(bookkeeping.Account{Code: 7399, Name: "Custom", IsLeft: true}); err != nil {
+		t.Fatal(err)
+	}
+	tdb.Close()
+
+	// reopening the existing database with query parameters must not initialize its accounts
+	for _, dsn := range []string{f + "?_pragma=busy_timeout(5000)", "file:" + f + "?_pragma=busy_timeout(5000)"} {
+		tdb, err := bookkeeping.NewDB(dsn)
+		if err != nil {
+			t.Fatalf("NewDB(%s) error = %v", dsn, err)
+		}
+		accs, err := bookkeeping.NewDBAccounts(tdb).Fetch(bookkeeping.DBAccountsFetchOption{CodePattern: "7399"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(accs) != 1 {
+			t.Errorf("NewDB(%s) must keep the custom account 7399", dsn)
+		}
+		tdb.Close()
+	}
+
+	if _, err := bookkeeping.CreateDB(f+"?_pragma=busy_timeout(5000)", bookkeeping.DefaultAccountTemplate); err == nil {
+		t.Errorf("CreateDB() with query parameters must fail for the existing database")
+	}
+}
+
+func Test_DB_Backup_Restore(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "bookkeeping_test.db")
+	backup := filepath.Join(dir, "backups", "bookkeeping_test.db")
+
+	tdb, err := bookkeeping.NewDB(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jn := bookkeeping.NewDBJournals(tdb)
+	if err := jn.Insert(
+		bookkeeping.Journal{Date: date(2021, 1, 3), Code: 1110, Left: 100000},
+		bookkeeping.Journal{Date: date(2021, 1, 3), Code: 3100, Right: 100000},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tdb.Backup(backup); err != nil {
+		t.Fatal(err)
+	}
+	if err := tdb.Backup(backup); err == nil {
+		t.Error("Backup() must fail if the backup file exists")
+	}
+
+	if err := jn.Insert(
+		bookkeeping.Journal{Date: date(2021, 1, 4), Code: 1110, Left: 100000},
+		bookkeeping.Journal{Date: date(2021, 1, 4), Code: 3100, Right: 100000},
+	); err != nil {
+		t.Fatal(err)
+	}
+	tdb.Close()
+
+	if err := bookkeeping.RestoreDB(backup, f); err != nil {
+		t.Fatal(err)
+	}
+
+	tdb, err = bookkeeping.OpenDB(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tdb.Close()
+
+	items, err := bookkeeping.NewDBJournals(tdb).Fetch(bookkeeping.DBJournalsFetchOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Errorf("restored database must have 2 journals at the backup, but got %v", len(items))
+	}
+}
+
+func Test_DB_Delete(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "bookkeeping_test.db")
+
+	tdb, err := bookkeeping.NewDB(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tdb.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(f); !os.IsNotExist(err) {
+		t.Errorf("Delete() must delete the database file, but got %v", err)
+	}
+	if _, err := bookkeeping.NewDBJournals(tdb).Fetch(bookkeeping.DBJournalsFetchOption{}); err == nil {
+		t.Errorf("Delete() must close the database")
+	}
+}
+
+func Test_CreateDB_Template(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tmpl := range bookkeeping.AccountTemplates {
+		t.Run(tmpl.Name, func(t *testing.T) {
+			f := filepath.Join(dir, tmpl.Name+".db")
+			tdb, err := bookkeeping.CreateDB(f, tmpl.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tdb.Close()
+
+			other := "en"
+			if tmpl.Lang == "en" {
+				other = "ja"
+			}
+
+			dbAc := bookkeeping.NewDBAccounts(tdb)
+			accs, err := dbAc.Fetch(bookkeeping.DBAccountsFetchOption{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			localized, err := dbAc.Fetch(bookkeeping.DBAccountsFetchOption{Lang: other})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(accs) == 0 || len(accs) != len(localized) {
+				t.Fatalf("accounts = %d, localized accounts = %d", len(accs), len(localized))
+			}
+			for i := range accs {
+				if accs[i].Name == localized[i].Name {
+					t.Errorf("account %d must have the name in %s, but got '%s'", accs[i].Code, other, localized[i].Name)
+				}
+			}
+
+			if _, err := bookkeeping.CreateDB(f, tmpl.Name); err == nil {
+				t.Error("CreateDB() must fail if the database exists")
+			}
+		})
+	}
+
+	if _, err := bookkeeping.CreateDB(filepath.Join(dir, "unknown.db"), "unknown"); err == nil {
+		t.Error("CreateDB() must fail with unknown template")
+	}
+}
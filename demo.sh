@@ -7,6 +7,8 @@ fi
 
 $BK_CMD -version
 
+$BK_CMD init
+
 $BK_CMD post -date 20200501 \
     -left 1110/500000/会社設立 -right 3100/500000/会社設立
 
@@ -2,22 +2,190 @@ package bookkeeping
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+// Entry is a set of journals posted together, the debit and credit lines of which balance.
+type Entry struct {
+	ID        int          `json:"id"`
+	Date      sql.NullTime `json:"date"`
+	Memo      string       `json:"memo"`
+	CreatedAt time.Time    `json:"created_at"`
+
+	// ReversalOf is the ID of the entry this entry reverses, or 0.
+	ReversalOf int `json:"reversal_of,omitempty"`
+	// ReversedBy is the ID of the entry reversing this entry, or 0.
+	ReversedBy int `json:"reversed_by,omitempty"`
+
+	// Closing is true for a year-end closing entry, which is excluded from profit and loss statements.
+	Closing bool `json:"closing,omitempty"`
+
+	Journals []Journal `json:"journals"`
+}
+
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	return json.Marshal(struct {
+		entry
+		Date *string `json:"date"`
+	}{entry(e), jsonDate(e.Date)})
+}
+
 type Journal struct {
-	ID          int
-	Date        sql.NullTime
-	Code        int
-	Description string
-	Left        int
-	Right       int
+	ID          int          `json:"id"`
+	EntryID     int          `json:"entry_id"`
+	Date        sql.NullTime `json:"date"`
+	Code        int          `json:"code"`
+	Description string       `json:"description"`
+	// Currency is the currency of Left and Right, which are in its minor units.
+	// Empty currency is DefaultCurrency.
+	Currency string `json:"currency"`
+	Left     int    `json:"debit"`
+	Right    int    `json:"credit"`
+
+	// FuncLeft and FuncRight are Left and Right converted into the functional currency, DefaultCurrency.
+	FuncLeft  int `json:"functional_debit"`
+	FuncRight int `json:"functional_credit"`
+
+	// ReversalOf and ReversedBy are copied from the entry of the journal.
+	ReversalOf int `json:"reversal_of,omitempty"`
+	ReversedBy int `json:"reversed_by,omitempty"`
+
+	// Reconciled is true if the journal is cleared on the bank statement of StatementRef.
+	// Reconciled journals and their entries cannot be changed.
+	Reconciled   bool   `json:"reconciled,omitempty"`
+	StatementRef string `json:"statement_ref,omitempty"`
+
+	Account Account `json:"account"`
+}
+
+func (j Journal) MarshalJSON() ([]byte, error) {
+	type journal Journal
+	return json.Marshal(struct {
+		journal
+		Date *string `json:"date"`
+	}{journal(j), jsonDate(j.Date)})
+}
+
+// ExchangeRate is the rate of the quote currency per unit of the base currency at the date,
+// such as 150.25 for USD/JPY.
+type ExchangeRate struct {
+	Base  string       `json:"base"`
+	Quote string       `json:"quote"`
+	Date  sql.NullTime `json:"date"`
+	// Rate is a decimal number.
+	Rate string `json:"rate"`
+}
+
+func (r ExchangeRate) MarshalJSON() ([]byte, error) {
+	type exchangeRate ExchangeRate
+	return json.Marshal(struct {
+		exchangeRate
+		Date *string `json:"date"`
+	}{exchangeRate(r), jsonDate(r.Date)})
+}
+
+// BankReview is a bank statement line which no rule matched, posted to the suspense account until it is reviewed.
+type BankReview struct {
+	ID int `json:"id"`
+	// EntryID is the ID of the entry posted from the line.
+	EntryID int `json:"entry_id"`
+	// Code is the bank account of the statement.
+	Code     int          `json:"code"`
+	Suspense int          `json:"suspense"`
+	Date     sql.NullTime `json:"date"`
+	Payee    string       `json:"payee"`
+	// Amount is positive for deposits and negative for withdrawals.
+	Amount int `json:"amount"`
+	// ResolvedBy is the ID of the entry which moved the amount out of the suspense account, or 0.
+	ResolvedBy int `json:"resolved_by,omitempty"`
+}
 
-	Account Account
+func (r BankReview) MarshalJSON() ([]byte, error) {
+	type bankReview BankReview
+	return json.Marshal(struct {
+		bankReview
+		Date *string `json:"date"`
+	}{bankReview(r), jsonDate(r.Date)})
+}
+
+type Customer struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Invoice is a sales invoice to a customer, posted as an entry debiting accounts receivable.
+// Amounts are in DefaultCurrency.
+type Invoice struct {
+	ID           int          `json:"id"`
+	CustomerID   int          `json:"customer_id"`
+	CustomerName string       `json:"customer_name"`
+	Date         sql.NullTime `json:"date"`
+	Amount       int          `json:"amount"`
+	Memo         string       `json:"memo"`
+	// EntryID is the ID of the entry posted on issuance.
+	EntryID int `json:"entry_id"`
+	// Paid is the sum of the payments applied to the invoice.
+	Paid int `json:"paid"`
+}
+
+func (inv Invoice) MarshalJSON() ([]byte, error) {
+	type invoice Invoice
+	return json.Marshal(struct {
+		invoice
+		Date *string `json:"date"`
+	}{invoice(inv), jsonDate(inv.Date)})
+}
+
+// Outstanding returns the amount of the invoice not paid yet.
+func (inv Invoice) Outstanding() int {
+	return inv.Amount - inv.Paid
+}
+
+// InvoicePayment is a part of a payment applied to an invoice.
+type InvoicePayment struct {
+	ID        int          `json:"id"`
+	InvoiceID int          `json:"invoice_id"`
+	EntryID   int          `json:"entry_id"`
+	Date      sql.NullTime `json:"date"`
+	Amount    int          `json:"amount"`
+}
+
+func (p InvoicePayment) MarshalJSON() ([]byte, error) {
+	type invoicePayment InvoicePayment
+	return json.Marshal(struct {
+		invoicePayment
+		Date *string `json:"date"`
+	}{invoicePayment(p), jsonDate(p.Date)})
 }
 
 type Account struct {
-	Code   int
-	Name   string
-	IsBS   bool
-	IsLeft bool
+	Code   int    `json:"code"`
+	Name   string `json:"name"`
+	IsBS   bool   `json:"is_bs"`
+	IsLeft bool   `json:"is_debit"`
+
+	// Inactive account cannot be used for new journals, but remains in reports.
+	Inactive bool `json:"inactive"`
+}
+
+// truncateDay returns the calendar date of the time at midnight in UTC, which is how dates are compared and stored.
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// endOfDay returns the last instant of the calendar date of the time, to fetch the rows stored
+// at any time of the day with a filter of dates on or before it.
+func endOfDay(t time.Time) time.Time {
+	return truncateDay(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// jsonDate formats a date as 'yyyy-mm-dd' for JSON, or nil if the date is null.
+func jsonDate(d sql.NullTime) *string {
+	if !d.Valid {
+		return nil
+	}
+	s := d.Time.Format("2006-01-02")
+	return &s
 }
@@ -0,0 +1,27 @@
+package bookkeeping_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_Journal_MarshalJSON(t *testing.T) {
+	j := bookkeeping.Journal{
+		ID: 1, EntryID: 2, Code: 1110, Left: 500,
+		Date: date(2020, 5, 1),
+	}
+	b, err := json.Marshal(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["date"] != "2020-05-01" || got["entry_id"] != float64(2) || got["debit"] != float64(500) {
+		t.Errorf("json.Marshal(Journal) = %s", b)
+	}
+}
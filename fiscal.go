@@ -0,0 +1,228 @@
+package bookkeeping
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	settingFiscalYearStartMonth = "fiscal_year_start_month"
+	settingClosedThrough        = "closed_through"
+
+	// retainedEarningsCode is the account which closing entries move net income into.
+	retainedEarningsCode = 3300
+
+	settingDateFormat = "2006-01-02"
+)
+
+// FiscalYearStartMonth returns the first month of fiscal years. The default is January.
+func (bk *Bookkeeping) FiscalYearStartMonth() (time.Month, error) {
+	v, err := bk.dbSt.Get(settingFiscalYearStartMonth)
+	if err != nil {
+		return 0, err
+	}
+	if v == "" {
+		return time.January, nil
+	}
+
+	m, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fiscal year start month setting '%s': %w", v, err)
+	}
+	return time.Month(m), nil
+}
+
+// SetFiscalYearStartMonth sets the first month of fiscal years.
+// It cannot be changed once a fiscal year is closed.
+func (bk *Bookkeeping) SetFiscalYearStartMonth(m time.Month) error {
+	if m < time.January || m > time.December {
+		return fmt.Errorf("fiscal year start month must be 1 to 12, but got %d", m)
+	}
+
+	closed, err := bk.ClosedThrough()
+	if err != nil {
+		return err
+	}
+	if !closed.IsZero() {
+		return fmt.Errorf("fiscal year start month cannot be changed after closing, closed through %s", closed.Format("2006/01/02"))
+	}
+
+	return bk.dbSt.Set(settingFiscalYearStartMonth, strconv.Itoa(int(m)))
+}
+
+// FiscalYear returns the first and the last date of the fiscal year, which starts in the year.
+func (bk *Bookkeeping) FiscalYear(year int) (time.Time, time.Time, error) {
+	m, err := bk.FiscalYearStartMonth()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	start := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(1, 0, -1), nil
+}
+
+// ClosedThrough returns the last date of the last closed fiscal year, or zero time if no fiscal year is closed.
+func (bk *Bookkeeping) ClosedThrough() (time.Time, error) {
+	v, err := bk.dbSt.Get(settingClosedThrough)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(settingDateFormat, v)
+}
+
+// Close closes the fiscal year, which starts in the year.
+// It posts a closing entry on the last date of the fiscal year, which moves the balances of P/L accounts
+// into retained earnings (3300), and locks the period so that no entry can be posted on or before the date.
+// Fiscal years must be closed in order, and the last date must not be locked by Lock.
+// It returns the ID of the closing entry, or 0 if nothing to close.
+func (bk *Bookkeeping) Close(year int) (int, error) {
+	start, end, err := bk.FiscalYear(year)
+	if err != nil {
+		return 0, err
+	}
+
+	closed, err := bk.ClosedThrough()
+	if err != nil {
+		return 0, err
+	}
+	if !closed.IsZero() {
+		if !end.After(closed) {
+			return 0, fmt.Errorf("fiscal year %d is already closed, closed through %s", year, closed.Format("2006/01/02"))
+		}
+		if !start.Equal(closed.AddDate(0, 0, 1)) {
+			return 0, fmt.Errorf("fiscal year %d must be closed first", year-1)
+		}
+	} else {
+		prev, err := bk.dbJn.Fetch(DBJournalsFetchOption{Before: sql.NullTime{Time: start.AddDate(0, 0, -1), Valid: true}})
+		if err != nil {
+			return 0, err
+		}
+		if len(prev) > 0 {
+			return 0, fmt.Errorf("fiscal year %d must be closed first", year-1)
+		}
+	}
+
+	if _, err := bk.fetchAccount(retainedEarningsCode); err != nil {
+		return 0, fmt.Errorf("retained earnings account %d is required to close fiscal years: %w", retainedEarningsCode, err)
+	}
+
+	jn, err := bk.dbJn.Fetch(DBJournalsFetchOption{
+		After:          sql.NullTime{Time: start, Valid: true},
+		Before:         sql.NullTime{Time: end, Valid: true},
+		ExcludeClosing: true,
+	}.CodeRange(4000, 9999))
+	if err != nil {
+		return 0, err
+	}
+
+	// raw balances are debit minus credit
+	raw := make(map[int]int)
+	for _, j := range jn {
+		raw[j.Code] += j.FuncLeft - j.FuncRight
+	}
+	codes := make([]int, 0, len(raw))
+	for code := range raw {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	d := sql.NullTime{Time: end, Valid: true}
+	e := Entry{Date: d, Memo: fmt.Sprintf("Closing of fiscal year %d", year), Closing: true}
+	netRaw := 0
+	for _, code := range codes {
+		switch {
+		case raw[code] > 0:
+			e.Journals = append(e.Journals, Journal{Date: d, Code: code, Right: raw[code]})
+		case raw[code] < 0:
+			e.Journals = append(e.Journals, Journal{Date: d, Code: code, Left: -raw[code]})
+		}
+		netRaw += raw[code]
+	}
+
+	switch {
+	case netRaw < 0:
+		e.Journals = append(e.Journals, Journal{Date: d, Code: retainedEarningsCode, Right: -netRaw, Description: "Net income"})
+	case netRaw > 0:
+		e.Journals = append(e.Journals, Journal{Date: d, Code: retainedEarningsCode, Left: netRaw, Description: "Net loss"})
+	}
+
+	// the closing entry and the closed date are written together, so that a failure leaves neither of them
+	entries := []Entry{}
+	if len(e.Journals) > 0 {
+		if err := bk.validateSystemEntry(&e); err != nil {
+			return 0, err
+		}
+		entries = append(entries, e)
+	}
+
+	ids, err := bk.dbSt.SetWithEntries(settingClosedThrough, end.Format(settingDateFormat), entries)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// openingDate returns the first date after the last closed fiscal year ending on or before the date,
+// or zero time if there is no such fiscal year.
+// Balances of P/L accounts before the opening date are already moved into retained earnings.
+func (bk *Bookkeeping) openingDate(date time.Time) (time.Time, error) {
+	closed, err := bk.ClosedThrough()
+	if err != nil || closed.IsZero() {
+		return time.Time{}, err
+	}
+
+	if !date.Before(closed) {
+		return closed.AddDate(0, 0, 1), nil
+	}
+
+	start, err := bk.fiscalYearStart(date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end := start.AddDate(1, 0, -1); !end.After(date) {
+		return end.AddDate(0, 0, 1), nil
+	}
+	return start, nil
+}
+
+// fiscalYearStart returns the first date of the fiscal year which contains the date.
+func (bk *Bookkeeping) fiscalYearStart(date time.Time) (time.Time, error) {
+	m, err := bk.FiscalYearStartMonth()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	start := time.Date(date.Year(), m, 1, 0, 0, 0, 0, time.UTC)
+	if start.After(date) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start, nil
+}
+
+// unclosedPL returns the profit and loss statement through the date, of which the net income
+// is not carried into retained earnings by closing entries yet.
+func (bk *Bookkeeping) unclosedPL(date time.Time) (PL, error) {
+	end := date
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start, err := bk.openingDate(end)
+	if err != nil {
+		return PL{}, err
+	}
+	// the fiscal year ending on the date is closed
+	if start.After(end) {
+		return PL{Currency: DefaultCurrency}, nil
+	}
+	return bk.FetchPL(FetchPLOpts{Start: start, End: date})
+}
@@ -0,0 +1,159 @@
+package bookkeeping_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_Close(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	if err := bk.SetFiscalYearStartMonth(time.April); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bk.Close(2021); err == nil {
+		t.Error("Close() must fail before the previous fiscal year is closed")
+	}
+
+	id, err := bk.Close(2020)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == 0 {
+		t.Fatal("Close() must post a closing entry")
+	}
+
+	e, err := bk.FetchEntry(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Closing || !e.Date.Time.Equal(date(2021, 3, 31).Time) {
+		t.Errorf("closing entry must be dated 2021/03/31, but got %+v", e)
+	}
+
+	closed, err := bk.ClosedThrough()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closed.Equal(date(2021, 3, 31).Time) {
+		t.Errorf("ClosedThrough() must be 2021/03/31, but got %v", closed)
+	}
+
+	pl, err := bk.FetchPL(bookkeeping.FetchPLOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.NetIncome != 0 {
+		t.Errorf("pl.NetIncome after the closed fiscal year must be 0, but got %v", pl.NetIncome)
+	}
+
+	pl, err = bk.FetchPL(bookkeeping.FetchPLOpts{Start: date(2020, 4, 1).Time, End: date(2021, 3, 31).Time})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.NetIncome != 1000000 {
+		t.Errorf("pl.NetIncome of the closed fiscal year must be 1000000, but got %v", pl.NetIncome)
+	}
+
+	// the statement at the year-end date is of the closed fiscal year
+	for _, d := range []time.Time{date(2021, 3, 30).Time, date(2021, 3, 31).Time} {
+		pl, err = bk.FetchPL(bookkeeping.FetchPLOpts{End: d})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pl.NetIncome != 1000000 || pl.NetSales == 0 {
+			t.Errorf("pl.NetIncome through %v must be 1000000, but got %+v", d, pl)
+		}
+	}
+
+	for _, d := range []time.Time{date(2020, 6, 1).Time, date(2021, 3, 31).Time, {}} {
+		bs, err := bk.FetchBS(bookkeeping.FetchBSOpts{Date: d})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bs.RetainedErnings != 1000000 || bs.TotalLiabilitiesAndEquity != 2960000 {
+			t.Errorf("bs at %v must have RetainedErnings 1000000 and TotalLiabilitiesAndEquity 2960000, but got %+v", d, bs)
+		}
+	}
+
+	if err := bk.Post([]bookkeeping.Journal{
+		{Date: date(2021, 3, 31), Code: 7300, Left: 1000},
+		{Date: date(2021, 3, 31), Code: 1110, Right: 1000},
+	}); err == nil {
+		t.Error("Post() must fail in a closed fiscal year")
+	}
+	if _, err := bk.Close(2020); err == nil {
+		t.Error("Close() must fail for a closed fiscal year")
+	}
+	if err := bk.SetFiscalYearStartMonth(time.January); err == nil {
+		t.Error("SetFiscalYearStartMonth() must fail after closing")
+	}
+
+	id, err = bk.Close(2021)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 0 {
+		t.Errorf("Close() must post nothing for a fiscal year without P/L journals, but got entry %v", id)
+	}
+}
+
+func Test_Close_InactiveAccount(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	if err := bk.DeactivateAccount(7300); err != nil {
+		t.Fatal(err)
+	}
+
+	// entries generated from posted journals may post to deactivated accounts
+	if _, err := bk.Reverse(3, date(2020, 6, 1).Time); err != nil {
+		t.Fatalf("Reverse() of an entry of a deactivated account error = %v", err)
+	}
+	if _, err := bk.Close(2020); err != nil {
+		t.Fatalf("Close() of a fiscal year with a deactivated account error = %v", err)
+	}
+
+	pl, err := bk.FetchPL(bookkeeping.FetchPLOpts{Start: date(2021, 1, 1).Time})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.NetIncome != 0 {
+		t.Errorf("pl.NetIncome after the closed fiscal year must be 0, but got %v", pl.NetIncome)
+	}
+}
+
+func Test_FiscalYear(t *testing.T) {
+	tdb := NewTestDB(t)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	start, end, err := bk.FiscalYear(2020)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !start.Equal(date(2020, 1, 1).Time) || !end.Equal(date(2020, 12, 31).Time) {
+		t.Errorf("default fiscal year 2020 must be 2020/01/01 - 2020/12/31, but got %v - %v", start, end)
+	}
+
+	if err := bk.SetFiscalYearStartMonth(13); err == nil {
+		t.Error("SetFiscalYearStartMonth(13) must fail")
+	}
+	if err := bk.SetFiscalYearStartMonth(time.April); err != nil {
+		t.Fatal(err)
+	}
+	start, end, err = bk.FiscalYear(2020)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !start.Equal(date(2020, 4, 1).Time) || !end.Equal(date(2021, 3, 31).Time) {
+		t.Errorf("fiscal year 2020 must be 2020/04/01 - 2021/03/31, but got %v - %v", start, end)
+	}
+}
@@ -0,0 +1,257 @@
+package bookkeeping
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// Accounts for unrealized foreign exchange gain and loss posted by Revalue.
+const (
+	fxGainCode = 8100
+	fxLossCode = 8200
+)
+
+// SetRates stores exchange rates, replacing the rate of the same currency pair and date.
+// Rates are dated by calendar date.
+// The rates of the caller are not modified.
+func (bk *Bookkeeping) SetRates(rates ...ExchangeRate) error {
+	normalized := make([]ExchangeRate, 0, len(rates))
+	for _, r := range rates {
+		if _, err := CurrencyExponent(r.Base); err != nil {
+			return err
+		}
+		if _, err := CurrencyExponent(r.Quote); err != nil {
+			return err
+		}
+		if r.Base == r.Quote {
+			return fmt.Errorf("base and quote currency must be different, but got %s/%s", r.Base, r.Quote)
+		}
+		if !r.Date.Valid {
+			return fmt.Errorf("date of rate %s/%s is required", r.Base, r.Quote)
+		}
+		rat, ok := new(big.Rat).SetString(r.Rate)
+		if !ok || rat.Sign() <= 0 {
+			return fmt.Errorf("rate of %s/%s must be a positive decimal number, but got '%s'", r.Base, r.Quote, r.Rate)
+		}
+		r.Rate = rat.FloatString(decimalDigits(r.Rate))
+		r.Date.Time = truncateDay(r.Date.Time)
+		normalized = append(normalized, r)
+	}
+
+	return bk.dbFx.Insert(normalized...)
+}
+
+type FetchRatesOpts struct {
+	Base  string
+	Quote string
+}
+
+func (bk *Bookkeeping) FetchRates(opt FetchRatesOpts) ([]ExchangeRate, error) {
+	return bk.dbFx.Fetch(DBExchangeRatesFetchOption{Base: opt.Base, Quote: opt.Quote})
+}
+
+// rate returns the latest rate of base/quote on or before the calendar date.
+// If only quote/base rate is available, its reciprocal is used.
+func (bk *Bookkeeping) rate(base, quote string, date time.Time) (*big.Rat, error) {
+	before := sql.NullTime{Time: endOfDay(date), Valid: true}
+
+	rates, err := bk.dbFx.Fetch(DBExchangeRatesFetchOption{Base: base, Quote: quote, Before: before, Latest: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) > 0 {
+		r, _ := new(big.Rat).SetString(rates[0].Rate)
+		return r, nil
+	}
+
+	rates, err = bk.dbFx.Fetch(DBExchangeRatesFetchOption{Base: quote, Quote: base, Before: before, Latest: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) > 0 {
+		r, _ := new(big.Rat).SetString(rates[0].Rate)
+		return r.Inv(r), nil
+	}
+
+	return nil, fmt.Errorf("exchange rate of %s/%s on or before %s is not found", base, quote, date.Format("2006/01/02"))
+}
+
+// convert converts an amount in minor units of the currency into minor units of the functional currency
+// by the rate at the date, rounding half away from zero.
+func (bk *Bookkeeping) convert(amount int, currency string, date time.Time) (int, error) {
+	if currency == DefaultCurrency || amount == 0 {
+		return amount, nil
+	}
+
+	r, err := bk.rate(currency, DefaultCurrency, date)
+	if err != nil {
+		return 0, err
+	}
+
+	fromExp, err := CurrencyExponent(currency)
+	if err != nil {
+		return 0, err
+	}
+	toExp, _ := CurrencyExponent(DefaultCurrency)
+
+	v := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(amount)), r)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil))
+	if toExp > fromExp {
+		v.Mul(v, scale)
+	} else {
+		v.Quo(v, scale)
+	}
+
+	return roundRat(v), nil
+}
+
+// convertEntry sets the functional currency amounts of journals which are not set yet.
+// The rounding difference of each currency whose debit and credit balance in the currency
+// is put on the last credit journal in the currency, so that the currency also balances in the functional currency.
+func (bk *Bookkeeping) convertEntry(e *Entry) error {
+	type sums struct{ left, right, funcLeft, funcRight int }
+	byCurrency := make(map[string]*sums)
+
+	for i, j := range e.Journals {
+		c := journalCurrency(j)
+		if j.FuncLeft == 0 && j.FuncRight == 0 {
+			l, err := bk.convert(j.Left, c, e.Date.Time)
+			if err != nil {
+				return err
+			}
+			r, err := bk.convert(j.Right, c, e.Date.Time)
+			if err != nil {
+				return err
+			}
+			e.Journals[i].FuncLeft, e.Journals[i].FuncRight = l, r
+		}
+
+		s, ok := byCurrency[c]
+		if !ok {
+			s = &sums{}
+			byCurrency[c] = s
+		}
+		s.left += j.Left
+		s.right += j.Right
+		s.funcLeft += e.Journals[i].FuncLeft
+		s.funcRight += e.Journals[i].FuncRight
+	}
+
+	for c, s := range byCurrency {
+		if s.left == 0 || s.left != s.right || s.funcLeft == s.funcRight {
+			continue
+		}
+		for i := len(e.Journals) - 1; i >= 0; i-- {
+			if journalCurrency(e.Journals[i]) == c && e.Journals[i].Right > 0 {
+				e.Journals[i].FuncRight += s.funcLeft - s.funcRight
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Revalue posts an entry of unrealized foreign exchange gain or loss for the foreign currency balances
+// of assets and liabilities at the date, so that their functional currency amounts match the rates at the date.
+// Gains are posted to non-operating incomes (8100), and losses to non-operating expences (8200).
+// It returns the ID of the posted entry, or 0 if nothing to revalue.
+func (bk *Bookkeeping) Revalue(date time.Time) (int, error) {
+	jn, err := bk.dbJn.Fetch(DBJournalsFetchOption{
+		Before: sql.NullTime{Time: date, Valid: true},
+	}.CodeRange(1000, 2999))
+	if err != nil {
+		return 0, err
+	}
+
+	type key struct {
+		code     int
+		currency string
+	}
+	// raw balances are debit minus credit
+	fcRaw := make(map[key]int)
+	funcRaw := make(map[key]int)
+	keys := []key{}
+	for _, j := range jn {
+		k := key{j.Code, journalCurrency(j)}
+		if k.currency == DefaultCurrency {
+			continue
+		}
+		if _, ok := fcRaw[k]; !ok {
+			keys = append(keys, k)
+		}
+		fcRaw[k] += j.Left - j.Right
+		funcRaw[k] += j.FuncLeft - j.FuncRight
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].code != keys[j].code {
+			return keys[i].code < keys[j].code
+		}
+		return keys[i].currency < keys[j].currency
+	})
+
+	d := sql.NullTime{Time: date, Valid: true}
+	e := Entry{Date: d, Memo: fmt.Sprintf("FX revaluation as of %s", date.Format("2006/01/02"))}
+	gain, loss := 0, 0
+	for _, k := range keys {
+		target, err := bk.convert(fcRaw[k], k.currency, date)
+		if err != nil {
+			return 0, err
+		}
+
+		diff := target - funcRaw[k]
+		if diff == 0 {
+			continue
+		}
+
+		j := Journal{Date: d, Code: k.code, Currency: k.currency, Description: "FX revaluation " + k.currency}
+		if diff > 0 {
+			j.FuncLeft = diff
+			gain += diff
+		} else {
+			j.FuncRight = -diff
+			loss -= diff
+		}
+		e.Journals = append(e.Journals, j)
+	}
+
+	if gain > 0 {
+		e.Journals = append(e.Journals, Journal{Date: d, Code: fxGainCode, Right: gain, Description: "Unrealized FX gain"})
+	}
+	if loss > 0 {
+		e.Journals = append(e.Journals, Journal{Date: d, Code: fxLossCode, Left: loss, Description: "Unrealized FX loss"})
+	}
+
+	if len(e.Journals) == 0 {
+		return 0, nil
+	}
+
+	return bk.postSystemEntry(e)
+}
+
+func roundRat(r *big.Rat) int {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(r.Num().Sign())))
+	}
+	return int(q.Int64())
+}
+
+// decimalDigits returns the number of digits after the decimal point of a decimal number string.
+func decimalDigits(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == '.' {
+			return len(s) - i - 1
+		}
+	}
+	return 0
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
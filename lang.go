@@ -0,0 +1,50 @@
+package bookkeeping
+
+import "fmt"
+
+// settingAccountsLang is the language of account names as added, set by the chart of accounts template.
+const settingAccountsLang = "accounts_lang"
+
+// SetLang sets the language of account names and section names in fetched accounts, journals and reports.
+// Accounts without the localized name are shown with the name as added.
+// Empty lang shows names as added and section names in English.
+func (bk *Bookkeeping) SetLang(lang string) {
+	bk.lang = lang
+}
+
+// AccountsLang returns the language of account names as added.
+// Databases created before chart of accounts templates have the accounts of the default template.
+func (bk *Bookkeeping) AccountsLang() (string, error) {
+	v, err := bk.dbSt.Get(settingAccountsLang)
+	if err != nil || v != "" {
+		return v, err
+	}
+
+	t, err := findAccountTemplate(DefaultAccountTemplate)
+	return t.Lang, err
+}
+
+// FetchAccountNames returns the localized names of accounts by account code and language.
+func (bk *Bookkeeping) FetchAccountNames() (map[int]map[string]string, error) {
+	return bk.dbAc.FetchNames()
+}
+
+// SetAccountName sets the localized name of the account of the code in the language,
+// which must differ from the language of account names.
+func (bk *Bookkeeping) SetAccountName(code int, lang, name string) error {
+	if lang == "" || name == "" {
+		return fmt.Errorf("language and name are required")
+	}
+	if _, err := bk.fetchAccount(code); err != nil {
+		return err
+	}
+
+	accountsLang, err := bk.AccountsLang()
+	if err != nil {
+		return err
+	}
+	if lang == accountsLang {
+		return fmt.Errorf("account names are in '%s', which cannot be localized", lang)
+	}
+	return bk.dbAc.SetName(code, lang, name)
+}
@@ -0,0 +1,70 @@
+package bookkeeping_test
+
+import (
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_SetLang(t *testing.T) {
+	tdb, err := bookkeeping.CreateDB(":memory:", "ja")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tdb.Close() })
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	if err := bk.Post([]bookkeeping.Journal{
+		{Date: date(2021, 1, 3), Code: 1110, Left: 100000},
+		{Date: date(2021, 1, 3), Code: 3100, Right: 100000},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	fetchName := func() string {
+		t.Helper()
+		accs, err := bk.FetchAc(bookkeeping.FetchAcOpts{CodeFilter: "1110"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return accs[0].Name
+	}
+
+	if got := fetchName(); got != "現金及び預金" {
+		t.Errorf("account name = %s, want 現金及び預金", got)
+	}
+
+	bk.SetLang("en")
+	if got := fetchName(); got != "Cash and Deposits" {
+		t.Errorf("account name in en = %s, want Cash and Deposits", got)
+	}
+
+	gl, err := bk.FetchGL(bookkeeping.FetchGLOpts{AccountIDList: []int{1110}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gl[0].Account.Name; got != "Cash and Deposits" {
+		t.Errorf("ledger account name in en = %s, want Cash and Deposits", got)
+	}
+
+	// renaming in another language changes only the localized name
+	if err := bk.RenameAccount(1110, "Cash"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fetchName(); got != "Cash" {
+		t.Errorf("account name in en = %s, want Cash", got)
+	}
+	bk.SetLang("")
+	if got := fetchName(); got != "現金及び預金" {
+		t.Errorf("account name = %s, want 現金及び預金", got)
+	}
+
+	bk.SetLang("ja")
+	bs, err := bk.FetchBSDetail(bookkeeping.FetchBSOpts{Date: date(2021, 12, 31).Time})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bs.Sections[0].Name; got != "資産" {
+		t.Errorf("section name in ja = %s, want 資産", got)
+	}
+}
@@ -0,0 +1,70 @@
+package bookkeeping
+
+import (
+	"fmt"
+	"time"
+)
+
+const settingLockedThrough = "locked_through"
+
+// LockedError is the error of posting, editing or deleting an entry dated on or before the locked-through date.
+type LockedError struct {
+	Date          time.Time
+	LockedThrough time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("entry date %s is locked, books are locked through %s",
+		e.Date.Format("2006/01/02"), e.LockedThrough.Format("2006/01/02"))
+}
+
+// Lock locks the books through the date, so that entries dated on or before the date cannot be posted or changed.
+func (bk *Bookkeeping) Lock(through time.Time) error {
+	if through.IsZero() {
+		return fmt.Errorf("locked-through date is required")
+	}
+
+	return bk.dbSt.Set(settingLockedThrough, through.Format(settingDateFormat))
+}
+
+// Unlock removes the lock by Lock. Closed fiscal years remain locked.
+func (bk *Bookkeeping) Unlock() error {
+	return bk.dbSt.Set(settingLockedThrough, "")
+}
+
+// LockedThrough returns the later of the date locked by Lock and the last date of the closed fiscal years,
+// or zero time if the books are not locked.
+func (bk *Bookkeeping) LockedThrough() (time.Time, error) {
+	closed, err := bk.ClosedThrough()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	v, err := bk.dbSt.Get(settingLockedThrough)
+	if err != nil || v == "" {
+		return closed, err
+	}
+
+	locked, err := time.Parse(settingDateFormat, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid locked-through setting '%s': %w", v, err)
+	}
+
+	if locked.After(closed) {
+		return locked, nil
+	}
+	return closed, nil
+}
+
+// checkLock returns *LockedError if the date is on or before the locked-through date, compared by calendar date.
+func (bk *Bookkeeping) checkLock(date time.Time) error {
+	locked, err := bk.LockedThrough()
+	if err != nil {
+		return err
+	}
+
+	if !locked.IsZero() && !truncateDay(date).After(truncateDay(locked)) {
+		return &LockedError{Date: date, LockedThrough: locked}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package bookkeeping_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_Lock(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	if err := bk.Lock(date(2020, 5, 31).Time); err != nil {
+		t.Fatal(err)
+	}
+
+	jn := func(d int) []bookkeeping.Journal {
+		return []bookkeeping.Journal{
+			{Date: date(2020, 5, d), Code: 7300, Left: 1000},
+			{Date: date(2020, 5, d), Code: 1110, Right: 1000},
+		}
+	}
+
+	err := bk.Post(jn(31))
+	var lockedErr *bookkeeping.LockedError
+	if !errors.As(err, &lockedErr) {
+		t.Fatalf("Post() on the locked-through date must return *LockedError, but got %v", err)
+	}
+	if !lockedErr.LockedThrough.Equal(date(2020, 5, 31).Time) {
+		t.Errorf("LockedError.LockedThrough must be 2020/05/31, but got %v", lockedErr.LockedThrough)
+	}
+
+	// the locked-through date is locked all day
+	afternoon := date(2020, 5, 31).Time.Add(15 * time.Hour)
+	if err := bk.Post([]bookkeeping.Journal{
+		{Date: sql.NullTime{Time: afternoon, Valid: true}, Code: 7300, Left: 1000},
+		{Date: sql.NullTime{Time: afternoon, Valid: true}, Code: 1110, Right: 1000},
+	}); !errors.As(err, &lockedErr) {
+		t.Errorf("Post() in the afternoon of the locked-through date must return *LockedError, but got %v", err)
+	}
+
+	if _, err := bk.Reverse(1, date(2020, 5, 20).Time); !errors.As(err, &lockedErr) {
+		t.Errorf("Reverse() on a locked date must return *LockedError, but got %v", err)
+	}
+
+	if err := bk.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bk.Post(jn(31)); err != nil {
+		t.Errorf("Post() after Unlock() must succeed, but got %v", err)
+	}
+
+	locked, err := bk.LockedThrough()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked.IsZero() {
+		t.Errorf("LockedThrough() after Unlock() must be zero, but got %v", locked)
+	}
+}
+
+func Test_Lock_ClosedFiscalYear(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	if _, err := bk.Close(2020); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bk.Lock(date(2020, 6, 30).Time); err != nil {
+		t.Fatal(err)
+	}
+	locked, err := bk.LockedThrough()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked.Equal(date(2020, 12, 31).Time) {
+		t.Errorf("LockedThrough() must be the end of the closed fiscal year 2020/12/31, but got %v", locked)
+	}
+
+	if err := bk.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	err = bk.Post([]bookkeeping.Journal{
+		{Date: date(2020, 12, 1), Code: 7300, Left: 1000},
+		{Date: date(2020, 12, 1), Code: 1110, Right: 1000},
+	})
+	var lockedErr *bookkeeping.LockedError
+	if !errors.As(err, &lockedErr) {
+		t.Errorf("Post() in a closed fiscal year must return *LockedError after Unlock(), but got %v", err)
+	}
+}
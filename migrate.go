@@ -0,0 +1,191 @@
+package bookkeeping
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const migrationsDir = "_embed/sql/migrations"
+
+// Migration is a versioned schema change under _embed/sql/migrations, named as <version>_<name>.sql.
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// AppliedAt is the time the migration was applied, or zero time if it is pending.
+	AppliedAt time.Time `json:"applied_at"`
+
+	sql string
+}
+
+// Pending returns true if the migration is not applied yet.
+func (m Migration) Pending() bool {
+	return m.AppliedAt.IsZero()
+}
+
+func loadMigrations() ([]Migration, error) {
+	files, err := fs.ReadDir(sqlFiles, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Migration, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || path.Ext(f.Name()) != ".sql" {
+			continue
+		}
+
+		base := strings.TrimSuffix(f.Name(), ".sql")
+		i := strings.Index(base, "_")
+		if i < 0 {
+			return nil, fmt.Errorf("migration file name must be <version>_<name>.sql, but got '%s'", f.Name())
+		}
+		v, err := strconv.Atoi(base[:i])
+		if err != nil {
+			return nil, fmt.Errorf("migration file name must be <version>_<name>.sql, but got '%s'", f.Name())
+		}
+
+		b, err := sqlFiles.ReadFile(path.Join(migrationsDir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, Migration{Version: v, Name: base[i+1:], sql: string(b)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+
+	return res, nil
+}
+
+func (d *DB) initSchemaVersion() error {
+	_, err := d.dbConn.Exec(`
+	create table if not exists schema_version(
+		version integer not null primary key,
+		name text,
+		applied_at datetime
+	)`)
+	return err
+}
+
+// Migrations returns all of the migrations ordered by version, with the time each migration was applied.
+func (d *DB) Migrations() ([]Migration, error) {
+	if err := d.initSchemaVersion(); err != nil {
+		return nil, err
+	}
+
+	ms, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.dbConn.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var v int
+		var at time.Time
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, err
+		}
+		applied[v] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range ms {
+		ms[i].AppliedAt = applied[ms[i].Version]
+	}
+	return ms, nil
+}
+
+// SchemaVersion returns the version of the last applied migration, or 0 if no migration is applied.
+func (d *DB) SchemaVersion() (int, error) {
+	ms, err := d.Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	v := 0
+	for _, m := range ms {
+		if !m.Pending() {
+			v = m.Version
+		}
+	}
+	return v, nil
+}
+
+// Migrate applies the pending migrations in order of version, and returns the applied migrations.
+// Each migration is applied in its own database transaction.
+func (d *DB) Migrate() ([]Migration, error) {
+	ms, err := d.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []Migration{}
+	for _, m := range ms {
+		if !m.Pending() {
+			continue
+		}
+
+		m.AppliedAt = time.Now().UTC()
+		if err := d.applyMigration(m); err != nil {
+			return applied, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+func (d *DB) applyMigration(m Migration) error {
+	tx, err := d.dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.sql); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("insert into schema_version(version, name, applied_at) values(?, ?, ?)",
+		m.Version, m.Name, m.AppliedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// dropTables drops all of the tables in the database.
+func (d *DB) dropTables() error {
+	rows, err := d.dbConn.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return err
+	}
+
+	tables := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	for _, t := range tables {
+		if _, err := d.dbConn.Exec("drop table if exists " + t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
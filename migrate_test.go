@@ -0,0 +1,123 @@
+package bookkeeping_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_NewDB_Migrate(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "bookkeeping_test.db")
+
+	// database created by the versions before migrations
+	old, err := sql.Open("sqlite", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, q := range []string{
+		"create table accounts(code integer not null primary key, name text, is_bs boolean, is_left boolean)",
+		"create table journals(id integer not null primary key, date date, code integer, description text, left integer DEFAULT 0, right integer DEFAULT 0)",
+		"insert into accounts(code, name, is_bs, is_left) values (1110, '現金及び預金', TRUE, TRUE), (3100, '資本金', TRUE, FALSE)",
+	} {
+		if _, err := old.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := old.Exec("insert into journals(date, code, description, left, right) values (?, 1110, '会社設立', 500000, 0), (?, 3100, '会社設立', 0, 500000)", d, d); err != nil {
+		t.Fatal(err)
+	}
+	// journals without a date cannot be grouped into an entry by date
+	if _, err := old.Exec("insert into journals(code, description, left, right) values (1110, '不明', 1000, 0), (3100, '不明', 0, 1000)"); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+
+	tdb, err := bookkeeping.NewDB(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tdb.Close()
+
+	ms, err := tdb.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range ms {
+		if m.Pending() {
+			t.Errorf("migration %d %s must be applied by NewDB()", m.Version, m.Name)
+		}
+	}
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	bs, err := bk.FetchBS(bookkeeping.FetchBSOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs.TotalAssets != 501000 || bs.OwnersCapital != 501000 {
+		t.Errorf("bs must keep the journals before migration, but got %+v", bs)
+	}
+
+	e, err := bk.FetchEntry(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Journals) != 2 {
+		t.Errorf("journals of the same date must be grouped into an entry, but got %+v", e)
+	}
+	for _, id := range []int{2, 3} {
+		e, err := bk.FetchEntry(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(e.Journals) != 1 || e.Journals[0].ID != id+1 {
+			t.Errorf("journal %d without a date must have an entry of its own, but got %+v", id+1, e)
+		}
+	}
+	if _, err := bk.FetchGL(); err != nil {
+		t.Errorf("FetchGL() after migration error = %v", err)
+	}
+
+	// the suspense account of bank statement imports is added to the existing accounts
+	accs, err := bk.FetchAc(bookkeeping.FetchAcOpts{CodeFilter: "1190"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accs) != 1 || accs[0].Name != "仮勘定" {
+		t.Errorf("migration must add suspense account 1190 仮勘定, but got %+v", accs)
+	}
+	bk.SetLang("en")
+	if accs, err = bk.FetchAc(bookkeeping.FetchAcOpts{CodeFilter: "1190"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(accs) != 1 || accs[0].Name != "Suspense" {
+		t.Errorf("suspense account 1190 must be named Suspense in en, but got %+v", accs)
+	}
+
+	applied, err := tdb.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Migrate() must apply nothing after NewDB(), but got %v", applied)
+	}
+}
+
+func Test_SchemaVersion(t *testing.T) {
+	tdb := NewTestDB(t)
+
+	ms, err := tdb.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := tdb.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != ms[len(ms)-1].Version {
+		t.Errorf("SchemaVersion() must be the latest version %v, but got %v", ms[len(ms)-1].Version, v)
+	}
+}
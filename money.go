@@ -0,0 +1,100 @@
+package bookkeeping
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts without currency code,
+// and the functional currency in which financial statements are reported.
+const DefaultCurrency = "JPY"
+
+// currencyExponents is the number of decimal digits of the minor unit of each supported currency.
+var currencyExponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CNY": 2,
+	"AUD": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"HKD": 2,
+	"SGD": 2,
+}
+
+// CurrencyExponent returns the number of decimal digits of the minor unit of the currency.
+func CurrencyExponent(currency string) (int, error) {
+	exp, ok := currencyExponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("currency '%s' is not supported", currency)
+	}
+	return exp, nil
+}
+
+// Money is an amount in fixed-point minor units of its currency, such as cents for USD.
+type Money struct {
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+var moneyPattern = regexp.MustCompile(`^([0-9]+)(?:\.([0-9]+))?([A-Z]{3})?$`)
+
+// ParseMoney parses an amount with an optional currency code, such as '500000', '12.50USD' or '1200JPY'.
+// The amount without currency code is in DefaultCurrency.
+func ParseMoney(s string) (Money, error) {
+	m := moneyPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Money{}, fmt.Errorf("cannot parse '%s' as amount, format: <amount>[<currency code>]", s)
+	}
+
+	currency := m[3]
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	frac := m[2]
+	if len(frac) > exp {
+		return Money{}, fmt.Errorf("amount '%s' has more than %d decimal digits for %s", s, exp, currency)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	amount, err := strconv.Atoi(m[1] + frac)
+	if err != nil {
+		return Money{}, fmt.Errorf("cannot parse '%s' as amount: %w", s, err)
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// String formats the money with its currency code, such as '12.50USD'.
+func (m Money) String() string {
+	return FormatAmount(m.Amount, m.Currency) + m.Currency
+}
+
+// FormatAmount formats an amount in minor units as a decimal number of the currency, such as '12.50' for 1250 USD.
+func FormatAmount(amount int, currency string) string {
+	exp, ok := currencyExponents[currency]
+	if !ok || exp == 0 {
+		return strconv.Itoa(amount)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	s := strconv.Itoa(amount)
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
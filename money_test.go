@@ -0,0 +1,60 @@
+package bookkeeping_test
+
+import (
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_ParseMoney(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    bookkeeping.Money
+		wantErr bool
+	}{
+		{"500000", bookkeeping.Money{Amount: 500000, Currency: "JPY"}, false},
+		{"1200JPY", bookkeeping.Money{Amount: 1200, Currency: "JPY"}, false},
+		{"12.50USD", bookkeeping.Money{Amount: 1250, Currency: "USD"}, false},
+		{"12.5USD", bookkeeping.Money{Amount: 1250, Currency: "USD"}, false},
+		{"12USD", bookkeeping.Money{Amount: 1200, Currency: "USD"}, false},
+		{"0.05EUR", bookkeeping.Money{Amount: 5, Currency: "EUR"}, false},
+		{"12.505USD", bookkeeping.Money{}, true},
+		{"12.5", bookkeeping.Money{}, true},
+		{"12XYZ", bookkeeping.Money{}, true},
+		{"-12USD", bookkeeping.Money{}, true},
+		{"9999ab", bookkeeping.Money{}, true},
+		{"", bookkeeping.Money{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := bookkeeping.ParseMoney(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseMoney() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_FormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   int
+		currency string
+		want     string
+	}{
+		{500000, "JPY", "500000"},
+		{1250, "USD", "12.50"},
+		{5, "USD", "0.05"},
+		{-1250, "EUR", "-12.50"},
+		{-5, "EUR", "-0.05"},
+		{0, "USD", "0.00"},
+	}
+	for _, tt := range tests {
+		if got := bookkeeping.FormatAmount(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("FormatAmount(%v, %v) = %v, want %v", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
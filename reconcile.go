@@ -0,0 +1,171 @@
+package bookkeeping
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type ReconcileOpts struct {
+	// Code is the bank account to reconcile.
+	Code int
+	// StatementDate and StatementBalance are the closing date and balance of the bank statement,
+	// in the currency of the account.
+	StatementDate    time.Time
+	StatementBalance int
+	// StatementRef identifies the statement, such as '2021-04'. Default is the statement date.
+	StatementRef string
+	// Tick is the IDs of the uncleared journals which appear on the statement.
+	Tick []int
+}
+
+// Reconciliation is the state of a bank account against its statement.
+// Balances are in the normal side of the account, in the currency of the account.
+type Reconciliation struct {
+	Account          Account      `json:"account"`
+	Currency         string       `json:"currency"`
+	StatementDate    sql.NullTime `json:"statement_date"`
+	StatementBalance int          `json:"statement_balance"`
+	StatementRef     string       `json:"statement_ref"`
+	// BookBalance is the balance of all journals through the statement date.
+	BookBalance int `json:"book_balance"`
+	// ClearedBalance is the balance of the journals reconciled before and the ticked journals.
+	ClearedBalance int `json:"cleared_balance"`
+	// Uncleared is the journals not reconciled yet through the statement date, including the ticked journals.
+	Uncleared []Journal `json:"uncleared"`
+	Tick      []int     `json:"tick"`
+	// Difference is StatementBalance minus ClearedBalance, which must be zero to reconcile.
+	Difference int `json:"difference"`
+}
+
+func (r Reconciliation) MarshalJSON() ([]byte, error) {
+	type reconciliation Reconciliation
+	return json.Marshal(struct {
+		reconciliation
+		StatementDate *string `json:"statement_date"`
+	}{reconciliation(r), jsonDate(r.StatementDate)})
+}
+
+// Ticked reports whether the journal of the ID is ticked.
+func (r Reconciliation) Ticked(id int) bool {
+	for _, t := range r.Tick {
+		if t == id {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchReconciliation returns the balances of the account through the statement date with the ticked journals cleared,
+// without reconciling them.
+func (bk *Bookkeeping) FetchReconciliation(opt ReconcileOpts) (Reconciliation, error) {
+	if opt.StatementDate.IsZero() {
+		return Reconciliation{}, fmt.Errorf("statement date is required")
+	}
+	acc, err := bk.fetchAccount(opt.Code)
+	if err != nil {
+		return Reconciliation{}, err
+	}
+	if !acc.IsBS {
+		return Reconciliation{}, fmt.Errorf("account '%d' is not a balance sheet account", opt.Code)
+	}
+
+	journals, err := bk.dbJn.Fetch(DBJournalsFetchOption{
+		Code:   []int{opt.Code},
+		Before: sql.NullTime{Time: opt.StatementDate, Valid: true},
+		Lang:   bk.lang,
+	})
+	if err != nil {
+		return Reconciliation{}, err
+	}
+	sort.SliceStable(journals, func(i, j int) bool {
+		if !journals[i].Date.Time.Equal(journals[j].Date.Time) {
+			return journals[i].Date.Time.Before(journals[j].Date.Time)
+		}
+		return journals[i].ID < journals[j].ID
+	})
+
+	r := Reconciliation{
+		Account:          acc,
+		Currency:         DefaultCurrency,
+		StatementDate:    sql.NullTime{Time: opt.StatementDate, Valid: true},
+		StatementBalance: opt.StatementBalance,
+		StatementRef:     opt.StatementRef,
+		Uncleared:        []Journal{},
+		Tick:             []int{},
+	}
+	if r.StatementRef == "" {
+		r.StatementRef = opt.StatementDate.Format("2006-01-02")
+	}
+
+	ticks := make(map[int]bool, len(opt.Tick))
+	for _, id := range opt.Tick {
+		ticks[id] = true
+	}
+
+	for i, j := range journals {
+		currency := j.Currency
+		if currency == "" {
+			currency = DefaultCurrency
+		}
+		if i == 0 {
+			r.Currency = currency
+		} else if currency != r.Currency {
+			return Reconciliation{}, fmt.Errorf("account '%d' has journals in %s and %s, which cannot be reconciled", opt.Code, r.Currency, currency)
+		}
+
+		amount := j.Left - j.Right
+		if !acc.IsLeft {
+			amount = -amount
+		}
+		r.BookBalance += amount
+
+		switch {
+		case j.Reconciled:
+			r.ClearedBalance += amount
+		case ticks[j.ID]:
+			r.ClearedBalance += amount
+			r.Uncleared = append(r.Uncleared, j)
+			r.Tick = append(r.Tick, j.ID)
+			delete(ticks, j.ID)
+		default:
+			r.Uncleared = append(r.Uncleared, j)
+		}
+	}
+
+	for _, id := range opt.Tick {
+		if ticks[id] {
+			return Reconciliation{}, fmt.Errorf("journal '%d' is not an uncleared journal of account '%d' through %s",
+				id, opt.Code, opt.StatementDate.Format("2006/01/02"))
+		}
+	}
+
+	r.Difference = r.StatementBalance - r.ClearedBalance
+	return r, nil
+}
+
+// Reconcile marks the ticked journals as reconciled on the statement, after which they cannot be changed.
+// It fails unless the cleared balance agrees with the statement balance.
+func (bk *Bookkeeping) Reconcile(opt ReconcileOpts) (Reconciliation, error) {
+	r, err := bk.FetchReconciliation(opt)
+	if err != nil {
+		return r, err
+	}
+	if r.Difference != 0 {
+		return r, fmt.Errorf("cleared balance %s differs from statement balance %s by %s",
+			FormatAmount(r.ClearedBalance, r.Currency), FormatAmount(r.StatementBalance, r.Currency), FormatAmount(r.Difference, r.Currency))
+	}
+
+	if err := bk.dbJn.Reconcile(r.Tick, r.StatementRef); err != nil {
+		return r, err
+	}
+	for i, j := range r.Uncleared {
+		if r.Ticked(j.ID) {
+			r.Uncleared[i].Reconciled = true
+			r.Uncleared[i].StatementRef = r.StatementRef
+		}
+	}
+	return r, nil
+}
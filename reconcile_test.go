@@ -0,0 +1,118 @@
+package bookkeeping_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_Reconcile(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "reconcile_test.db")
+	tdb, err := bookkeeping.CreateDB(f, bookkeeping.DefaultAccountTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tdb.Close() })
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	ids, err := bk.ImportBankLines(1110, 1190, []bookkeeping.BankLine{
+		{Date: date(2021, 4, 1).Time, Payee: "A", Amount: 300000, Contra: 3100},
+		{Date: date(2021, 4, 20).Time, Payee: "B", Amount: -1200, Contra: 7300},
+		{Date: date(2021, 4, 30).Time, Payee: "C", Amount: -5000, Contra: 7300},
+		{Date: date(2021, 5, 2).Time, Payee: "D", Amount: -800, Contra: 7300},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// journal IDs of the bank account in the entries
+	bankJournal := func(entryID int) int {
+		t.Helper()
+		e, err := bk.FetchEntry(entryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, j := range e.Journals {
+			if j.Code == 1110 {
+				return j.ID
+			}
+		}
+		t.Fatalf("entry %d has no journal of 1110", entryID)
+		return 0
+	}
+
+	opt := bookkeeping.ReconcileOpts{
+		Code:             1110,
+		StatementDate:    date(2021, 4, 30).Time,
+		StatementBalance: 298800,
+		Tick:             []int{bankJournal(ids[0]), bankJournal(ids[1])},
+	}
+	r, err := bk.FetchReconciliation(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.BookBalance != 293800 || r.ClearedBalance != 298800 || r.Difference != 0 || len(r.Uncleared) != 3 {
+		t.Errorf("FetchReconciliation() got book %v, cleared %v, difference %v and %v uncleared, want 293800, 298800, 0 and 3",
+			r.BookBalance, r.ClearedBalance, r.Difference, len(r.Uncleared))
+	}
+
+	// journals after the statement date cannot be ticked
+	if _, err := bk.FetchReconciliation(bookkeeping.ReconcileOpts{
+		Code: 1110, StatementDate: date(2021, 4, 30).Time, Tick: []int{bankJournal(ids[3])},
+	}); err == nil {
+		t.Errorf("FetchReconciliation() must fail to tick a journal after the statement date")
+	}
+
+	// the statement balance must agree
+	wrong := opt
+	wrong.StatementBalance = 300000
+	if _, err := bk.Reconcile(wrong); err == nil {
+		t.Errorf("Reconcile() must fail with difference")
+	}
+
+	if _, err := bk.Reconcile(opt); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = bk.FetchReconciliation(bookkeeping.ReconcileOpts{Code: 1110, StatementDate: date(2021, 5, 31).Time, StatementBalance: 293000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ClearedBalance != 298800 || len(r.Uncleared) != 2 || r.Difference != -5800 {
+		t.Errorf("FetchReconciliation() after reconcile got cleared %v, difference %v and %v uncleared, want 298800, -5800 and 2",
+			r.ClearedBalance, r.Difference, len(r.Uncleared))
+	}
+
+	e, err := bk.FetchEntry(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range e.Journals {
+		if j.Code == 1110 && (!j.Reconciled || j.StatementRef != "2021-04-30") {
+			t.Errorf("journal %d must be reconciled on 2021-04-30, but got %v and '%s'", j.ID, j.Reconciled, j.StatementRef)
+		}
+	}
+
+	// reconciled journals cannot be changed even by SQL
+	conn, err := sql.Open("sqlite", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec("update journals set left = 1 where id = ?", bankJournal(ids[0])); err == nil {
+		t.Errorf("reconciled journal must not be updated")
+	}
+	if _, err := conn.Exec("delete from transactions where id = ?", ids[1]); err == nil {
+		t.Errorf("entry with reconciled journal must not be deleted")
+	}
+	if _, err := conn.Exec("update journals set description = 'x' where id = ?", bankJournal(ids[2])); err != nil {
+		t.Errorf("uncleared journal must be updated, but got %v", err)
+	}
+
+	// reversing a reconciled entry posts a new entry
+	if _, err := bk.Reverse(ids[1], date(2021, 5, 3).Time); err != nil {
+		t.Errorf("Reverse() of reconciled entry must succeed, but got %v", err)
+	}
+}
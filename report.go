@@ -0,0 +1,203 @@
+package bookkeeping
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Section is a group of account lines in a detailed report, following the code system of accounts.
+type Section struct {
+	// Prefix is the account code prefix of the section, such as "11" for current assets.
+	Prefix   string           `json:"prefix"`
+	Name     string           `json:"name"`
+	Lines    []AccountBalance `json:"lines,omitempty"`
+	Sections []Section        `json:"sections,omitempty"`
+	Total    int              `json:"total"`
+}
+
+// AccountBalance is a balance of an account in a detailed report.
+type AccountBalance struct {
+	Account Account `json:"account"`
+	Balance int     `json:"balance"`
+}
+
+type sectionDef struct {
+	prefix   string
+	name     string
+	children []sectionDef
+}
+
+var bsSectionDefs = []sectionDef{
+	{"1", "Assets", []sectionDef{
+		{"11", "Current Assets", []sectionDef{
+			{"111", "Cash and Deposits", nil},
+			{"112", "Trade Receivables", nil},
+			{"113", "Inventories", nil},
+		}},
+		{"12", "Noncurrent Assets", []sectionDef{
+			{"121", "Property, Plant and Equipment", nil},
+			{"122", "Intangible Assets", nil},
+		}},
+	}},
+	{"2", "Liabilities", []sectionDef{
+		{"21", "Current Liabilities", nil},
+		{"22", "Noncurrent Liabilities", nil},
+	}},
+	{"3", "Equity", []sectionDef{
+		{"31", "Owner's Capital", nil},
+		{"32", "Capital Surplus", nil},
+		{"33", "Retained Earnings", nil},
+	}},
+}
+
+var plSectionDefs = []sectionDef{
+	{"4", "Net Sales", []sectionDef{
+		{"41", "Merchandise Sales", nil},
+		{"42", "Product Sales", nil},
+	}},
+	{"5", "Cost Sales", []sectionDef{
+		{"51", "Beginning Inventory", nil},
+		{"52", "Purchases and Manufacturing Cost", nil},
+		{"53", "Ending Inventory", nil},
+	}},
+	{"6", "Manufacturing Costs", []sectionDef{
+		{"61", "Material Costs", nil},
+		{"62", "Labor Costs", nil},
+		{"63", "Manufacturing Expences", nil},
+	}},
+	{"7", "Operating Expences", []sectionDef{
+		{"71", "Selling Expences", nil},
+		{"72", "Personnel Expences", nil},
+		{"73", "General and Administrative Expences", nil},
+	}},
+	{"81", "Non Operating Incomes", nil},
+	{"82", "Non Operating Expences", nil},
+	{"83", "Extraordinary Incomes", nil},
+	{"84", "Extraordinary Expences", nil},
+	{"9", "Provision For Income Taxes", nil},
+}
+
+// netIncomeName is the name of the net income line in the balance sheet.
+const netIncomeName = "Net Income"
+
+// localNames are the names of sections and lines in reports by language, keyed by the English name.
+var localNames = map[string]map[string]string{
+	"ja": {
+		"Assets":                              "資産",
+		"Current Assets":                      "流動資産",
+		"Cash and Deposits":                   "現預金",
+		"Trade Receivables":                   "営業債権",
+		"Inventories":                         "棚卸資産",
+		"Noncurrent Assets":                   "固定資産",
+		"Property, Plant and Equipment":       "有形固定資産",
+		"Intangible Assets":                   "無形固定資産",
+		"Liabilities":                         "負債",
+		"Current Liabilities":                 "流動負債",
+		"Noncurrent Liabilities":              "固定負債",
+		"Equity":                              "純資産",
+		"Owner's Capital":                     "資本金",
+		"Capital Surplus":                     "資本剰余金",
+		"Retained Earnings":                   "利益剰余金",
+		"Net Sales":                           "売上高",
+		"Merchandise Sales":                   "商品売上高",
+		"Product Sales":                       "製品売上高",
+		"Cost Sales":                          "売上原価",
+		"Beginning Inventory":                 "期首棚卸高",
+		"Purchases and Manufacturing Cost":    "仕入高及び製造原価",
+		"Ending Inventory":                    "期末棚卸高",
+		"Manufacturing Costs":                 "製造原価",
+		"Material Costs":                      "材料費",
+		"Labor Costs":                         "人件費",
+		"Manufacturing Expences":              "経費",
+		"Operating Expences":                  "販売費及び一般管理費",
+		"Selling Expences":                    "販売費",
+		"Personnel Expences":                  "人件費",
+		"General and Administrative Expences": "経費",
+		"Non Operating Incomes":               "営業外収益",
+		"Non Operating Expences":              "営業外費用",
+		"Extraordinary Incomes":               "特別利益",
+		"Extraordinary Expences":              "特別損失",
+		"Provision For Income Taxes":          "法人税等",
+		netIncomeName:                         "当期純利益",
+	},
+}
+
+// localName returns the name of a section or a line in the language, or the English name if not localized.
+func localName(lang string, name string) string {
+	if n, ok := localNames[lang][name]; ok {
+		return n
+	}
+	return name
+}
+
+// accountBalances sums journals by account, ordered by account code.
+func accountBalances(jn []Journal) []AccountBalance {
+	byCode := make(map[int][]Journal)
+	for _, j := range jn {
+		byCode[j.Code] = append(byCode[j.Code], j)
+	}
+
+	res := make([]AccountBalance, 0, len(byCode))
+	for _, items := range byCode {
+		res = append(res, AccountBalance{Account: items[0].Account, Balance: SumJournal(items)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Account.Code < res[j].Account.Code })
+	return res
+}
+
+// buildSections places each line in the deepest section matching its account code.
+// Lines not bound to an account, such as net income, are placed in the section of the prefix key in extra.
+// Sections without any line are omitted, except for the top level sections.
+// Section names are in the language.
+func buildSections(defs []sectionDef, lines []AccountBalance, extra map[string][]AccountBalance, lang string) []Section {
+	res := make([]Section, 0, len(defs))
+	for _, def := range defs {
+		res = append(res, buildSection(def, lines, extra, lang))
+	}
+	return res
+}
+
+func buildSection(def sectionDef, lines []AccountBalance, extra map[string][]AccountBalance, lang string) Section {
+	sec := Section{Prefix: def.prefix, Name: localName(lang, def.name)}
+
+	matched := []AccountBalance{}
+	for _, l := range lines {
+		if strings.HasPrefix(strconv.Itoa(l.Account.Code), def.prefix) {
+			matched = append(matched, l)
+		}
+	}
+
+	for _, child := range def.children {
+		cs := buildSection(child, matched, extra, lang)
+		if len(cs.Lines) == 0 && len(cs.Sections) == 0 {
+			continue
+		}
+		sec.Sections = append(sec.Sections, cs)
+		sec.Total += cs.Total
+	}
+
+	for _, l := range matched {
+		if !inChildSection(def, l.Account.Code) {
+			sec.Lines = append(sec.Lines, l)
+			sec.Total += l.Balance
+		}
+	}
+
+	for _, l := range extra[def.prefix] {
+		sec.Lines = append(sec.Lines, l)
+		sec.Total += l.Balance
+	}
+
+	return sec
+}
+
+func inChildSection(def sectionDef, code int) bool {
+	c := strconv.Itoa(code)
+	for _, child := range def.children {
+		if strings.HasPrefix(c, child.prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package bookkeeping
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TrialBalanceLine is a row of the trial balance for an account.
+// Opening and Closing are balances on the normal side of the account, the same as SumJournal.
+type TrialBalanceLine struct {
+	Account Account `json:"account"`
+	Opening int     `json:"opening"`
+	Debit   int     `json:"debit"`
+	Credit  int     `json:"credit"`
+	Closing int     `json:"closing"`
+}
+
+type TrialBalance struct {
+	Start    time.Time          `json:"start"`
+	End      time.Time          `json:"end"`
+	Currency string             `json:"currency"`
+	Lines    []TrialBalanceLine `json:"lines"`
+
+	// OpeningDebit and OpeningCredit are the totals of opening balances on debit and credit side.
+	OpeningDebit  int `json:"opening_debit"`
+	OpeningCredit int `json:"opening_credit"`
+
+	TotalDebit  int `json:"total_debit"`
+	TotalCredit int `json:"total_credit"`
+
+	// ClosingDebit and ClosingCredit are the totals of closing balances on debit and credit side.
+	ClosingDebit  int `json:"closing_debit"`
+	ClosingCredit int `json:"closing_credit"`
+}
+
+// Check returns an error describing every imbalance of the totals.
+func (tb TrialBalance) Check() error {
+	msgs := []string{}
+	if tb.OpeningDebit != tb.OpeningCredit {
+		msgs = append(msgs, fmt.Sprintf("opening balance, debit: %v, credit: %v", tb.OpeningDebit, tb.OpeningCredit))
+	}
+	if tb.TotalDebit != tb.TotalCredit {
+		msgs = append(msgs, fmt.Sprintf("period total, debit: %v, credit: %v", tb.TotalDebit, tb.TotalCredit))
+	}
+	if tb.ClosingDebit != tb.ClosingCredit {
+		msgs = append(msgs, fmt.Sprintf("closing balance, debit: %v, credit: %v", tb.ClosingDebit, tb.ClosingCredit))
+	}
+
+	if len(msgs) > 0 {
+		return fmt.Errorf("trial balance is not balancing: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+type FetchTrialBalanceOpts struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FetchTrialBalance returns the trial balance of every account in the period in the functional currency.
+// Journals before Start are summed up into the opening balance.
+func (bk *Bookkeeping) FetchTrialBalance(opt FetchTrialBalanceOpts) (TrialBalance, error) {
+	tb := TrialBalance{Start: opt.Start, End: opt.End, Currency: DefaultCurrency}
+
+	accs, err := bk.dbAc.Fetch(DBAccountsFetchOption{Lang: bk.lang})
+	if err != nil {
+		return tb, err
+	}
+
+	dbOpt := DBJournalsFetchOption{}
+	if !opt.End.IsZero() {
+		dbOpt.Before = sql.NullTime{Time: opt.End, Valid: true}
+	}
+	jn, err := bk.dbJn.Fetch(dbOpt)
+	if err != nil {
+		return tb, err
+	}
+
+	// raw balances are debit minus credit
+	openingRaw := make(map[int]int)
+	debit := make(map[int]int)
+	credit := make(map[int]int)
+	for _, j := range jn {
+		if !opt.Start.IsZero() && j.Date.Time.Before(opt.Start) {
+			openingRaw[j.Code] += j.FuncLeft - j.FuncRight
+			continue
+		}
+		debit[j.Code] += j.FuncLeft
+		credit[j.Code] += j.FuncRight
+	}
+
+	for _, a := range accs {
+		closingRaw := openingRaw[a.Code] + debit[a.Code] - credit[a.Code]
+
+		line := TrialBalanceLine{
+			Account: a,
+			Opening: normalBalance(a, openingRaw[a.Code]),
+			Debit:   debit[a.Code],
+			Credit:  credit[a.Code],
+			Closing: normalBalance(a, closingRaw),
+		}
+		tb.Lines = append(tb.Lines, line)
+
+		tb.TotalDebit += line.Debit
+		tb.TotalCredit += line.Credit
+		if raw := openingRaw[a.Code]; raw > 0 {
+			tb.OpeningDebit += raw
+		} else {
+			tb.OpeningCredit -= raw
+		}
+		if closingRaw > 0 {
+			tb.ClosingDebit += closingRaw
+		} else {
+			tb.ClosingCredit -= closingRaw
+		}
+	}
+
+	return tb, nil
+}
+
+// normalBalance converts a debit minus credit balance to the balance on the normal side of the account.
+func normalBalance(a Account, raw int) int {
+	if a.IsLeft {
+		return raw
+	}
+	return -raw
+}
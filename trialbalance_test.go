@@ -0,0 +1,55 @@
+package bookkeeping_test
+
+import (
+	"testing"
+
+	"github.com/yoskeoka/bookkeeping"
+)
+
+func Test_FetchTrialBalance(t *testing.T) {
+	tdb := NewTestDB(t)
+	initAccounts(t, tdb)
+	insertTransactionData(t, tdb)
+
+	bk := bookkeeping.NewBookkeeping(tdb)
+	tb, err := bk.FetchTrialBalance(bookkeeping.FetchTrialBalanceOpts{
+		Start: date(2020, 5, 10).Time,
+		End:   date(2020, 5, 20).Time,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tb.Check(); err != nil {
+		t.Error(err)
+	}
+
+	if len(tb.Lines) != 24 {
+		t.Errorf("trial balance must have lines of every account, but got %v lines", len(tb.Lines))
+	}
+
+	var cash bookkeeping.TrialBalanceLine
+	for _, l := range tb.Lines {
+		if l.Account.Code == 1110 {
+			cash = l
+		}
+	}
+	if cash.Opening != 1050000 || cash.Debit != 4000000 || cash.Credit != 2290000 || cash.Closing != 2760000 {
+		t.Errorf("code 1110 line must be opening 1050000, debit 4000000, credit 2290000, closing 2760000, but got %+v", cash)
+	}
+
+	if tb.TotalDebit != 12300000 {
+		t.Errorf("tb.TotalDebit must be 12300000, but got %v", tb.TotalDebit)
+	}
+}
+
+func Test_TrialBalance_Check(t *testing.T) {
+	tb := bookkeeping.TrialBalance{
+		OpeningDebit: 100, OpeningCredit: 100,
+		TotalDebit: 200, TotalCredit: 150,
+		ClosingDebit: 300, ClosingCredit: 250,
+	}
+	if err := tb.Check(); err == nil {
+		t.Errorf("Check() of imbalanced trial balance must return error")
+	}
+}